@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+	"spotomusic/internal/transfer"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan.json>",
+	Short: "Applies a plan saved by a dry run",
+	Long: `This command executes a plan written by 'transfer --dry-run --plan-out'.
+Playlists are created and the planned videos are added without searching
+YouTube again, so applying only costs insert quota.
+
+Examples:
+  spotomusic transfer --all --dry-run --plan-out plan.json
+  spotomusic apply plan.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reportPath, _ := cmd.Flags().GetString("report")
+		if reportPath != "" {
+			if err := transfer.ValidateReportPath(reportPath); err != nil {
+				return err
+			}
+		}
+
+		plan, err := transfer.LoadPlan(args[0])
+		if err != nil {
+			return err
+		}
+
+		creates, adds, _ := plan.Counts()
+		fmt.Printf("Applying plan from %s: %d playlists to create, %d tracks to add (~%d quota units)\n",
+			plan.CreatedAt.Local().Format("2006-01-02 15:04:05"), creates, adds, plan.EstimatedQuota())
+
+		ctx := cmd.Context()
+		transferService := transfer.NewService(appConfig, plan.Options)
+		run := history.Run{
+			ID:        transfer.NewRunID(),
+			StartedAt: time.Now().UTC(),
+			Options:   transferService.Options(),
+		}
+
+		results, err := transferService.ApplyPlan(ctx, plan)
+		finishRun(ctx, run, results, reportPath)
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("apply interrupted")
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().String("report", "", "Write a per-track report (.json, .csv, .md or .html)")
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"spotomusic/internal/auth"
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// authCmd groups the commands that manage the saved logins
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manages the YouTube and Spotify logins",
+}
+
+// authLoginCmd represents the auth login command
+var authLoginCmd = &cobra.Command{
+	Use:   "login [youtube|spotify]",
+	Short: "Logs in to YouTube (default) or Spotify",
+	Long: `This command runs the OAuth login for a service and saves the token, replacing
+any saved one. By default the login URL is opened in a browser that is
+redirected back to a temporary server on 127.0.0.1.
+
+On machines without a browser use --device: a code and a verification URL are
+printed, the code is entered on any other device and the command waits for
+the approval. Device login needs a YouTube OAuth client of type "TVs and
+Limited Input devices"; Spotify does not support it.
+
+Examples:
+  spotomusic auth login
+  spotomusic auth login --device
+  spotomusic auth login youtube --read-only
+  spotomusic auth login spotify`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"youtube", "spotify"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := "youtube"
+		if len(args) > 0 {
+			service = strings.ToLower(args[0])
+		}
+		device, _ := cmd.Flags().GetBool("device")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+
+		var flow auth.Flow = auth.Loopback{}
+		if device {
+			flow = auth.Device{}
+		}
+
+		var err error
+		switch service {
+		case "youtube":
+			err = youtube.Login(cmd.Context(), youtube.Options{ReadOnly: readOnly}, flow)
+		case "spotify":
+			err = spotify.Login(cmd.Context(), flow)
+		default:
+			return fmt.Errorf("unknown service %q (expected youtube or spotify)", args[0])
+		}
+		if errors.Is(err, auth.ErrDeviceFlowUnsupported) {
+			return fmt.Errorf("%s does not support device login; log in with a browser and copy the token file to this machine", service)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Logged in to %s.\n", service)
+		return nil
+	},
+}
+
+// authLogoutCmd represents the auth logout command
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout [youtube|spotify]",
+	Short: "Removes the saved tokens of a service, or of both",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		services, err := authServices(args)
+		if err != nil {
+			return err
+		}
+		for _, service := range services {
+			if service == "youtube" {
+				err = youtube.Logout()
+			} else {
+				err = spotify.Logout()
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Logged out of %s.\n", service)
+		}
+		return nil
+	},
+}
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status [youtube|spotify]",
+	Short: "Shows the saved logins",
+	Long: `This command shows the account or channel, the granted scopes and the access
+token expiry of each saved login. It never starts a login. Looking up the
+account refreshes an expired access token.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		services, err := authServices(args)
+		if err != nil {
+			return err
+		}
+		for _, service := range services {
+			if service == "youtube" {
+				loggedIn := false
+				for _, readOnly := range []bool{false, true} {
+					status, err := youtube.Status(cmd.Context(), youtube.Options{ReadOnly: readOnly})
+					if errors.Is(err, auth.ErrNotLoggedIn) {
+						continue
+					}
+					loggedIn = true
+					label := "youtube"
+					if readOnly {
+						label = "youtube (read-only)"
+					}
+					printAuthStatus(label, status, err)
+				}
+				if !loggedIn {
+					fmt.Println("youtube: not logged in (run 'spotomusic auth login youtube')")
+				}
+				continue
+			}
+
+			status, err := spotify.Status(cmd.Context())
+			if errors.Is(err, auth.ErrNotLoggedIn) {
+				fmt.Println("spotify: not logged in (run 'spotomusic auth login spotify')")
+				continue
+			}
+			printAuthStatus("spotify", status, err)
+		}
+		return nil
+	},
+}
+
+// authRefreshCmd represents the auth refresh command
+var authRefreshCmd = &cobra.Command{
+	Use:   "refresh [youtube|spotify]",
+	Short: "Refreshes the saved access tokens",
+	Long: `This command exchanges the saved refresh token for a new access token and
+saves it. Tokens are also refreshed and saved automatically during runs; use
+this to check that a login still works before a long unattended transfer.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		services, err := authServices(args)
+		if err != nil {
+			return err
+		}
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+
+		var failed bool
+		for _, service := range services {
+			var status *auth.Status
+			if service == "youtube" {
+				status, err = youtube.Refresh(cmd.Context(), youtube.Options{ReadOnly: readOnly})
+			} else {
+				status, err = spotify.Refresh(cmd.Context())
+			}
+			if status == nil {
+				failed = true
+				fmt.Printf("%s: %v\n", service, err)
+				continue
+			}
+			printAuthStatus(service, status, err)
+		}
+		if failed {
+			return fmt.Errorf("not every token could be refreshed")
+		}
+		return nil
+	},
+}
+
+// authCredentialsCmd represents the auth credentials command
+var authCredentialsCmd = &cobra.Command{
+	Use:   "credentials <youtube|spotify> <file>",
+	Short: "Stores an OAuth client file in the secret store",
+	Long: `This command saves the OAuth client of a service in the secret store of the
+profile, so it no longer has to stay on disk in the clear. For YouTube the file
+is the client JSON downloaded from the Google Cloud Console, for Spotify a JSON
+file with client_id and client_secret.
+
+With SPOTOMUSIC_SECRET_KEY (a base64 encoded random key) or
+SPOTOMUSIC_SECRET_PASSPHRASE set, or secrets.backend set to encrypted, the
+secret store encrypts everything it keeps.
+
+Examples:
+  spotomusic auth credentials youtube ~/Downloads/client_secret.json --remove-file
+  spotomusic auth credentials spotify spotify-app.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		services, err := authServices(args[:1])
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("credentials dosyası okunamadı: %v", err)
+		}
+
+		if services[0] == "youtube" {
+			err = youtube.StoreCredentials(data)
+		} else {
+			err = spotify.StoreCredentials(data)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Stored the %s credentials.\n", services[0])
+
+		if remove, _ := cmd.Flags().GetBool("remove-file"); remove {
+			if err := os.Remove(args[1]); err != nil {
+				return fmt.Errorf("credentials dosyası silinemedi: %v", err)
+			}
+			fmt.Printf("Removed %s.\n", args[1])
+		}
+		return nil
+	},
+}
+
+// authServices returns the service named in args, or both
+func authServices(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"youtube", "spotify"}, nil
+	}
+	service := strings.ToLower(args[0])
+	if service != "youtube" && service != "spotify" {
+		return nil, fmt.Errorf("unknown service %q (expected youtube or spotify)", args[0])
+	}
+	return []string{service}, nil
+}
+
+// printAuthStatus prints a saved login. err is a failed account lookup or
+// a missing configuration.
+func printAuthStatus(label string, status *auth.Status, err error) {
+	if status == nil {
+		fmt.Printf("%s: %v\n", label, err)
+		return
+	}
+
+	fmt.Printf("%s:\n", label)
+	if err != nil {
+		fmt.Printf("  Account:  unknown (%v)\n", err)
+	} else {
+		fmt.Printf("  Account:  %s\n", status.Account)
+	}
+	fmt.Printf("  Scopes:   %s\n", strings.Join(status.Scopes, " "))
+
+	switch {
+	case status.Expiry.IsZero():
+		fmt.Printf("  Expires:  never\n")
+	case time.Until(status.Expiry) > 0:
+		fmt.Printf("  Expires:  %s (in %s)\n", status.Expiry.Local().Format("2006-01-02 15:04"), time.Until(status.Expiry).Round(time.Minute))
+	case status.Refreshable:
+		fmt.Printf("  Expires:  expired %s, refreshed on next use\n", status.Expiry.Local().Format("2006-01-02 15:04"))
+	default:
+		fmt.Printf("  Expires:  expired %s, log in again\n", status.Expiry.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("  Stored:   %s\n", status.Location)
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authStatusCmd, authRefreshCmd, authCredentialsCmd)
+	authLoginCmd.Flags().Bool("device", false, "Log in with a code entered on another device")
+	authLoginCmd.Flags().Bool("read-only", false, "Only request read access (YouTube)")
+	authRefreshCmd.Flags().Bool("read-only", false, "Refresh the read-only YouTube token")
+	authCredentialsCmd.Flags().Bool("remove-file", false, "Delete the file once it is stored")
+}
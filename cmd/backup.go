@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"spotomusic/internal/config"
+	"spotomusic/internal/spotify"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Saves an offline snapshot of your Spotify playlists",
+	Long: `This command fetches every configured playlist with all of its tracks and
+writes a versioned JSON snapshot. Snapshots are stored under
+~/.spotomusic/backups unless --output is given, and can be transferred later
+with 'spotomusic transfer --from snapshot:<file>'.
+
+Examples:
+  spotomusic backup
+  spotomusic backup --output playlists.json
+  spotomusic transfer --all --from snapshot:playlists.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			dir, err := config.Dir()
+			if err != nil {
+				return err
+			}
+			dir = filepath.Join(dir, "backups")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("backup directory oluşturulamadı: %v", err)
+			}
+			output = filepath.Join(dir, time.Now().Format("20060102-150405")+".json")
+		}
+
+		client, err := spotify.NewClient()
+		if err != nil {
+			return fmt.Errorf("failed to create Spotify client: %v", err)
+		}
+
+		snapshot, err := client.TakeSnapshot(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("snapshot alınamadı: %v", err)
+		}
+
+		tracks := 0
+		for _, playlist := range snapshot.Playlists {
+			fmt.Printf("  %s (%d tracks)\n", playlist.Playlist.Name, len(playlist.Tracks))
+			tracks += len(playlist.Tracks)
+		}
+
+		if err := snapshot.WriteFile(output); err != nil {
+			return err
+		}
+		fmt.Printf("Saved %d playlists with %d tracks to %s\n", len(snapshot.Playlists), tracks, output)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.Flags().StringP("output", "o", "", "Snapshot file (default: ~/.spotomusic/backups/<timestamp>.json)")
+}
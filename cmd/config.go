@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"spotomusic/internal/config"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Creates, shows and changes the configuration",
+	Long: `Settings are read from the --config file or the first of
+$HOME/.spotomusic.yaml, $HOME/.spotomusic/spotomusic.yaml and ./spotomusic.yaml,
+and can be overridden by environment variables and flags. These commands
+show the effective value of every setting and where it came from.
+
+With --profile the settings of that profile are shown and changed.
+
+Examples:
+  spotomusic config init
+  spotomusic config show
+  spotomusic config get transfer.max_retries
+  spotomusic config set matching.prefer topic
+  spotomusic config set spotify.playlist_links "https://open.spotify.com/playlist/a,https://open.spotify.com/playlist/b"
+  spotomusic config validate`,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Walks through the settings and writes the config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := *appConfig
+		if cfg.File != "" {
+			confirm := promptui.Prompt{
+				Label:     fmt.Sprintf("Update %s", cfg.File),
+				IsConfirm: true,
+			}
+			if _, err := confirm.Run(); err != nil {
+				fmt.Println("Nothing changed.")
+				return nil
+			}
+		}
+		if err := runConfigWizard(&cfg); err != nil {
+			return err
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("config kaydedilemedi: %v", err)
+		}
+		fmt.Printf("Configuration written to %s\n", cfg.File)
+		if cfg.YouTube.CredentialsFile == "" {
+			fmt.Println("Store the YouTube OAuth client with: spotomusic auth credentials youtube <file>")
+		}
+		fmt.Println("Log in with: spotomusic auth login")
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows every setting with its value and source",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := appConfig.File
+		if file == "" {
+			file = "none, using defaults"
+		}
+		fmt.Printf("Config file: %s\n", file)
+		fmt.Printf("Profile:     %s\n\n", appConfig.Profile.Name)
+
+		fmt.Printf("%-28s  %-30s  %s\n", "KEY", "VALUE", "SOURCE")
+		for _, key := range config.Keys() {
+			value, _ := appConfig.Get(key)
+			shown := formatConfigValue(value)
+			if sensitiveConfigKeys[key] && shown != "" {
+				shown = "********"
+			}
+			fmt.Printf("%-28s  %-30s  %s\n", key, shown, appConfig.Source(key))
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Prints the effective value of a setting and its source",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := appConfig.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s = %s  (%s)\n", args[0], formatConfigValue(value), appConfig.Source(args[0]))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Stores a setting in the config file",
+	Long: `Stores a setting in the config file. Lists are given comma separated.
+The file is rewritten, so comments in it are lost.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := appConfig.Source(args[0])
+		path, err := appConfig.Set(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		value, _ := appConfig.Get(args[0])
+		fmt.Printf("%s = %s written to %s\n", args[0], formatConfigValue(value), path)
+		if source.Kind == "flag" || source.Kind == "env" {
+			fmt.Printf("Note: %s still overrides it\n", source)
+		}
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks the configuration for invalid values",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := appConfig.Validate(); err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		file := appConfig.File
+		if file == "" {
+			file = "defaults"
+		}
+		fmt.Printf("%s Configuration is valid (%s)\n", green("✓"), file)
+		return nil
+	},
+}
+
+// sensitiveConfigKeys are masked by config show
+var sensitiveConfigKeys = map[string]bool{
+	"youtube.api_key": true,
+}
+
+// formatConfigValue prints lists comma separated like config set takes them
+func formatConfigValue(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// runConfigWizard asks for the settings needed to get started, offering the
+// current values as defaults
+func runConfigWizard(cfg *config.Config) error {
+	fmt.Println("Press Enter to keep the value shown. Leave the credentials files empty")
+	fmt.Println("to use the ones stored with 'spotomusic auth credentials'.")
+	fmt.Println()
+
+	var err error
+	if cfg.YouTube.CredentialsFile, err = promptConfig("YouTube OAuth client file", cfg.YouTube.CredentialsFile, validateOptionalFile); err != nil {
+		return err
+	}
+	if cfg.Spotify.CredentialsFile, err = promptConfig("Spotify app credentials file", cfg.Spotify.CredentialsFile, validateOptionalFile); err != nil {
+		return err
+	}
+
+	links, err := promptConfig("Spotify playlist links (comma separated)", strings.Join(cfg.Spotify.PlaylistLinks, ","), nil)
+	if err != nil {
+		return err
+	}
+	cfg.Spotify.PlaylistLinks = config.ParseList(links)
+
+	if cfg.Matching.Prefer, err = selectConfig("Preferred upload type", []string{"any", "topic", "official_video"}, cfg.Matching.Prefer); err != nil {
+		return err
+	}
+	deny, err := promptConfig("Channels never to pick (IDs or name patterns, comma separated)", strings.Join(cfg.Matching.DenyChannels, ","), nil)
+	if err != nil {
+		return err
+	}
+	cfg.Matching.DenyChannels = config.ParseList(deny)
+
+	if cfg.YouTube.Privacy, err = selectConfig("Privacy of created YouTube playlists", []string{"private", "unlisted", "public"}, cfg.YouTube.Privacy); err != nil {
+		return err
+	}
+
+	if cfg.Transfer.SkipExisting, err = confirmConfig("Skip playlists that already exist on the destination", cfg.Transfer.SkipExisting); err != nil {
+		return err
+	}
+	if cfg.Transfer.DryRun, err = confirmConfig("Only simulate transfers by default (dry run)", cfg.Transfer.DryRun); err != nil {
+		return err
+	}
+	concurrency, err := promptConfig("Tracks searched in parallel", strconv.Itoa(cfg.Transfer.Concurrency), func(input string) error {
+		if n, err := strconv.Atoi(input); err != nil || n < 1 {
+			return errors.New("enter a number of at least 1")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cfg.Transfer.Concurrency, _ = strconv.Atoi(concurrency)
+
+	cfg.Logging.Level, err = selectConfig("Log level", []string{"info", "debug", "warn", "error"}, cfg.Logging.Level)
+	return err
+}
+
+// promptConfig asks for a text value
+func promptConfig(label, current string, validate promptui.ValidateFunc) (string, error) {
+	prompt := promptui.Prompt{
+		Label:     label,
+		Default:   current,
+		AllowEdit: true,
+		Validate:  validate,
+	}
+	value, err := prompt.Run()
+	return strings.TrimSpace(value), err
+}
+
+// selectConfig asks for one of items, starting at current
+func selectConfig(label string, items []string, current string) (string, error) {
+	cursor := 0
+	for i, item := range items {
+		if item == current {
+			cursor = i
+		}
+	}
+	prompt := promptui.Select{
+		Label:     label,
+		Items:     items,
+		CursorPos: cursor,
+	}
+	_, value, err := prompt.Run()
+	return value, err
+}
+
+// confirmConfig asks a yes/no question
+func confirmConfig(label string, current bool) (bool, error) {
+	items := []string{"yes", "no"}
+	if !current {
+		items = []string{"no", "yes"}
+	}
+	value, err := selectConfig(label, items, items[0])
+	return value == "yes", err
+}
+
+// validateOptionalFile accepts an empty path or an existing file
+func validateOptionalFile(input string) error {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil
+	}
+	if _, err := os.Stat(input); err != nil {
+		return fmt.Errorf("file bulunamadı: %s", input)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -28,7 +28,7 @@ detailed information about what data is being extracted.`,
 		fmt.Println(strings.Repeat("=", 50))
 
 		// Get playlist info
-		playlist, err := client.GetPlaylistInfo(playlistID, playlistName)
+		playlist, err := client.GetPlaylistInfo(cmd.Context(), playlistID, playlistName)
 		if err != nil {
 			return fmt.Errorf("failed to get playlist info: %v", err)
 		}
@@ -40,7 +40,7 @@ detailed information about what data is being extracted.`,
 		fmt.Printf("Owner: %s\n", playlist.Owner)
 
 		// Get tracks
-		tracks, err := client.GetPlaylistTracks(playlistID)
+		tracks, err := client.GetPlaylistTracks(cmd.Context(), playlistID)
 		if err != nil {
 			return fmt.Errorf("failed to get tracks: %v", err)
 		}
@@ -51,7 +51,7 @@ detailed information about what data is being extracted.`,
 		}
 
 		// Save HTML for analysis
-		if err := client.SaveHTMLForAnalysis(playlistID); err != nil {
+		if err := client.SaveHTMLForAnalysis(cmd.Context(), playlistID); err != nil {
 			fmt.Printf("Warning: Could not save HTML for analysis: %v\n", err)
 		} else {
 			fmt.Printf("\nHTML saved to debug.html for analysis\n")
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/transfer"
+	"spotomusic/internal/youtube"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <spotify-playlist>",
+	Short: "Shows how the YouTube copy of a playlist drifted from Spotify",
+	Long: `This command compares a Spotify playlist with the YouTube playlist it was
+last transferred to. Tracks are paired through the recorded transfers first
+and by title otherwise. It lists tracks only on Spotify, videos only on
+YouTube and pairs that are out of order.
+
+Examples:
+  spotomusic diff 37i9dQZF1DXcBWIGoYBM5M
+  spotomusic diff https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M --format json
+  spotomusic diff 37i9dQZF1DXcBWIGoYBM5M --youtube-playlist PLxxxxxxxxxxxxxxxx`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+		youtubePlaylistID, _ := cmd.Flags().GetString("youtube-playlist")
+
+		playlistID := spotify.ParsePlaylistID(args[0])
+
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+		mirror, err := store.Mirror(playlistID)
+		if err != nil && youtubePlaylistID == "" {
+			return fmt.Errorf("%v (pass --youtube-playlist to compare anyway)", err)
+		}
+		if youtubePlaylistID != "" && (mirror.YouTubePlaylist == nil || mirror.YouTubePlaylist.ID != youtubePlaylistID) {
+			// A different playlist than the recorded one: titles only
+			mirror = transfer.TransferResult{YouTubePlaylist: &youtube.YouTubePlaylist{ID: youtubePlaylistID}}
+		}
+
+		transferService := transfer.NewService(appConfig, transfer.Options{YouTubeAPIKey: youtubeAPIKey()})
+		diff, err := transferService.DiffPlaylist(cmd.Context(), playlistID, mirror)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(diff)
+		}
+		printPlaylistDiff(diff)
+		return nil
+	},
+}
+
+// printPlaylistDiff prints a playlist diff as text
+func printPlaylistDiff(diff transfer.PlaylistDiff) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Printf("Spotify %s: %d tracks\n", diff.SourcePlaylistID, diff.SpotifyTracks)
+	fmt.Printf("YouTube %s: %d videos\n", diff.YouTubePlaylistID, diff.YouTubeVideos)
+	fmt.Printf("Paired: %d\n\n", diff.Paired)
+
+	if diff.InSync() {
+		fmt.Println(green("The YouTube playlist is in sync."))
+		return
+	}
+
+	if len(diff.OnlyOnSpotify) > 0 {
+		fmt.Printf("Only on Spotify (%d):\n", len(diff.OnlyOnSpotify))
+		for _, entry := range diff.OnlyOnSpotify {
+			fmt.Printf("  %s %3d. %s - %s\n", red("-"), entry.Position, entry.Track.Artist, entry.Track.Name)
+		}
+		fmt.Println()
+	}
+
+	if len(diff.OnlyOnYouTube) > 0 {
+		fmt.Printf("Only on YouTube (%d):\n", len(diff.OnlyOnYouTube))
+		for _, item := range diff.OnlyOnYouTube {
+			fmt.Printf("  %s %3d. %s [%s]\n", green("+"), item.Position+1, item.Video.Title, item.Video.ChannelName)
+		}
+		fmt.Println()
+	}
+
+	if len(diff.Moved) > 0 {
+		fmt.Printf("Order differs (%d):\n", len(diff.Moved))
+		for _, pair := range diff.Moved {
+			fmt.Printf("  %s %s - %s: Spotify #%d, YouTube #%d\n", yellow("~"),
+				pair.Track.Artist, pair.Track.Name, pair.Position, pair.Item.Position+1)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().String("format", "text", "Output format: text or json")
+	diffCmd.Flags().String("youtube-playlist", "", "Compare with this YouTube playlist instead of the recorded one")
+}
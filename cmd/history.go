@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Shows previous transfer runs",
+	Long: `Every transfer is recorded under ~/.spotomusic/history, or under
+~/.spotomusic/profiles/<name>/history for a named profile. Use these
+commands to see what was transferred when and compare runs.
+
+Examples:
+  spotomusic history list
+  spotomusic history show latest
+  spotomusic history diff 20240101-120000-3f9a2c1e 20240201-120000-b04d7e52`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists recorded transfer runs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+
+		runs, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			fmt.Println("No transfer runs recorded yet.")
+			return nil
+		}
+
+		fmt.Printf("%-24s  %-19s  %-9s  %-15s  %s\n", "RUN", "STARTED", "PLAYLISTS", "MATCHED/TOTAL", "NOTES")
+		for _, run := range runs {
+			total, matched, _ := run.Totals()
+			fmt.Printf("%-24s  %-19s  %-9d  %-15s  %s\n",
+				run.ID,
+				run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+				len(run.Playlists),
+				fmt.Sprintf("%d/%d", matched, total),
+				runNotes(run))
+		}
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <run>",
+	Short: "Shows the details of a transfer run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+
+		run, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+
+		fmt.Printf("Run:      %s\n", run.ID)
+		fmt.Printf("Started:  %s\n", run.StartedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("Finished: %s\n", run.FinishedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("Prefer:   %s, workers: %d\n", run.Options.Match.Prefer, run.Options.Concurrency)
+		if notes := runNotes(run); notes != "" {
+			fmt.Printf("Notes:    %s\n", notes)
+		}
+
+		for _, playlist := range run.Playlists {
+			fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+			fmt.Printf("%s (%s", playlist.PlaylistName, playlist.SourcePlaylistID)
+			if playlist.YouTubePlaylist != nil && playlist.YouTubePlaylist.ID != "" {
+				fmt.Printf(" -> %s", playlist.YouTubePlaylist.ID)
+			}
+			fmt.Printf(")\n")
+			fmt.Printf("Matched: %s, Failed: %s, Total: %d\n", green(playlist.MatchedTracks), red(playlist.FailedTracks), playlist.TotalTracks)
+
+			for _, track := range playlist.Tracks {
+				line := fmt.Sprintf("%3d. %s - %s", track.Position, track.Track.Artist, track.Track.Name)
+				if track.Failed() {
+					fmt.Printf("%s %s\n", line, red("["+string(track.Status)+"]"))
+				} else {
+					fmt.Printf("%s -> %s (score %d)\n", line, track.MatchTitle(), track.Score)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff <run1> <run2>",
+	Short: "Compares the track outcomes of two runs",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+
+		from, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+		to, err := store.Load(args[1])
+		if err != nil {
+			return err
+		}
+
+		fromTotal, fromMatched, _ := from.Totals()
+		toTotal, toMatched, _ := to.Totals()
+		fmt.Printf("%s: %d/%d matched\n", from.ID, fromMatched, fromTotal)
+		fmt.Printf("%s: %d/%d matched\n\n", to.ID, toMatched, toTotal)
+
+		diff := history.Diff(from, to)
+		if len(diff.Changes) == 0 {
+			fmt.Println("No differences in track outcomes.")
+			return nil
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		yellow := color.New(color.FgYellow).SprintFunc()
+
+		for _, change := range diff.Changes {
+			name := fmt.Sprintf("%s - %s", change.Artist, change.Name)
+			switch change.Kind() {
+			case "added":
+				fmt.Printf("%s %s [%s]\n", green("+"), name, change.After.Status)
+			case "removed":
+				fmt.Printf("%s %s [%s]\n", red("-"), name, change.Before.Status)
+			case "fixed":
+				fmt.Printf("%s %s: %s -> %s\n", green("✓"), name, change.Before.Status, change.After.MatchTitle())
+			case "regressed":
+				fmt.Printf("%s %s: %s -> %s\n", red("✗"), name, change.Before.MatchTitle(), change.After.Status)
+			case "video":
+				fmt.Printf("%s %s: %s (%d) -> %s (%d)\n", yellow("~"), name,
+					change.Before.MatchTitle(), change.Before.Score,
+					change.After.MatchTitle(), change.After.Score)
+			default:
+				fmt.Printf("%s %s: %s -> %s\n", yellow("~"), name, change.Before.Status, change.After.Status)
+			}
+		}
+		return nil
+	},
+}
+
+// runNotes summarises the flags of a run for listings
+func runNotes(run history.Run) string {
+	var notes []string
+	if run.Command != "" {
+		notes = append(notes, run.Command)
+	}
+	if run.DryRun {
+		notes = append(notes, "dry run")
+	}
+	if run.Interrupted {
+		notes = append(notes, "interrupted")
+	}
+	if run.Options.Snapshot != "" {
+		notes = append(notes, "from snapshot "+run.Options.Snapshot)
+	}
+	if run.Options.Export != "" {
+		notes = append(notes, "exported to "+run.Options.Export)
+	}
+	if run.RetryOf != "" {
+		notes = append(notes, "retry of "+run.RetryOf)
+	}
+	if run.UndoneAt != nil {
+		notes = append(notes, "undone")
+	}
+	return strings.Join(notes, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyDiffCmd)
+}
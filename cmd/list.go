@@ -19,7 +19,7 @@ and prepares them for transfer.`,
 			return fmt.Errorf("failed to create Spotify client: %v", err)
 		}
 
-		playlists, err := client.GetUserPlaylists()
+		playlists, err := client.GetUserPlaylists(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("failed to get playlists: %v", err)
 		}
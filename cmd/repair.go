@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/transfer"
+)
+
+// repairCmd represents the repair command
+var repairCmd = &cobra.Command{
+	Use:   "repair [spotify-playlist...]",
+	Short: "Replaces deleted, private and region-blocked videos in YouTube copies",
+	Long: `This command scans the YouTube copies of your playlists for videos that
+were taken down, made private or are blocked in your region, searches again
+for their Spotify tracks and swaps the replacements in at the same position.
+Without arguments every playlist in the transfer history is scanned.
+
+Examples:
+  spotomusic repair
+  spotomusic repair --region TR --dry-run
+  spotomusic repair 37i9dQZF1DXcBWIGoYBM5M --check-only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun := appConfig.Transfer.DryRun
+		checkOnly, _ := cmd.Flags().GetBool("check-only")
+		region, _ := cmd.Flags().GetString("region")
+
+		prefer, err := transfer.ParsePreference(appConfig.Matching.Prefer)
+		if err != nil {
+			return err
+		}
+
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+
+		playlistIDs := make([]string, 0, len(args))
+		for _, arg := range args {
+			playlistIDs = append(playlistIDs, spotify.ParsePlaylistID(arg))
+		}
+		if len(playlistIDs) == 0 {
+			if playlistIDs, err = store.Mirrored(); err != nil {
+				return err
+			}
+			if len(playlistIDs) == 0 {
+				fmt.Println("No transferred playlists recorded yet.")
+				return nil
+			}
+		}
+
+		ctx := cmd.Context()
+		transferService := maintenanceService(prefer)
+		run := history.Run{
+			ID:        transfer.NewRunID(),
+			StartedAt: time.Now().UTC(),
+			Command:   "repair",
+			Options:   transferService.Options(),
+		}
+
+		failed := false
+		for _, playlistID := range playlistIDs {
+			if ctx.Err() != nil {
+				break
+			}
+			fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+
+			mirror, mirrorErr := store.Mirror(playlistID)
+			if mirrorErr != nil {
+				fmt.Printf("Error: %v\n", mirrorErr)
+				failed = true
+				continue
+			}
+			fmt.Printf("%s (%s -> %s)\n", mirror.PlaylistName, playlistID, mirror.YouTubePlaylist.ID)
+
+			result, verifyErr := transferService.VerifyPlaylist(ctx, playlistID, mirror, transfer.VerifyOptions{
+				Fix:             !checkOnly,
+				DryRun:          dryRun,
+				UnavailableOnly: true,
+				Region:          region,
+			})
+			if verifyErr != nil && len(result.Checks) == 0 {
+				fmt.Printf("Error: %v\n", verifyErr)
+				failed = true
+				continue
+			}
+
+			hint := ""
+			if checkOnly {
+				hint = "Replace them with: spotomusic repair " + playlistID
+			}
+			printVerifyResult(result, hint)
+
+			if replaced := result.ReplacementResults(mirror.YouTubePlaylist); len(replaced.Tracks) > 0 {
+				run.Playlists = append(run.Playlists, replaced)
+			}
+		}
+
+		// Record replacements so later diffs and verifies know the new videos
+		if len(run.Playlists) > 0 {
+			run.FinishedAt = time.Now().UTC()
+			run.Interrupted = ctx.Err() != nil
+			recordRun(run)
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("repair interrupted")
+		}
+		if failed {
+			return fmt.Errorf("some playlists could not be checked")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+	repairCmd.Flags().Bool("check-only", false, "Only report unavailable videos, do not replace them")
+	repairCmd.Flags().String("region", "", "Also treat videos blocked in this country as unavailable (ISO code, e.g. TR)")
+}
@@ -1,13 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"spotomusic/internal/config"
 	"spotomusic/internal/logger"
+	"spotomusic/internal/profile"
 )
 
 var cfgFile string
 
+// appConfig is the configuration loaded before every command runs
+var appConfig *config.Config
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "spotomusic",
@@ -20,30 +29,72 @@ Features:
 - Transfers selected playlists to YouTube
 - High success rate with smart song matching algorithm
 - Detailed progress reports`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return initConfig(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// SIGINT and SIGTERM cancel the command context so long running commands can
+// stop cleanly; a second signal kills the process as usual.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.spotomusic.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.spotomusic.yaml or $HOME/.spotomusic/spotomusic.yaml)")
 	rootCmd.PersistentFlags().Bool("verbose", false, "verbose output")
+	rootCmd.PersistentFlags().String("log-level", "info", "log level: trace, debug, info, warn or error")
 	rootCmd.PersistentFlags().Bool("dry-run", false, "simulation only, don't perform actual transfer")
+	rootCmd.PersistentFlags().String("profile", "", "account profile to use (default is $SPOTOMUSIC_PROFILE or \"default\")")
 
 	// Bind flags to viper
-	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
-	viper.BindPFlag("dry-run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	config.BindFlag("logging.verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	config.BindFlag("logging.level", rootCmd.PersistentFlags().Lookup("log-level"))
+	config.BindFlag("transfer.dry_run", rootCmd.PersistentFlags().Lookup("dry-run"))
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
+// profileName returns the profile named by --profile or SPOTOMUSIC_PROFILE
+func profileName(cmd *cobra.Command) string {
+	name, _ := cmd.Flags().GetString("profile")
+	if name == "" {
+		name = os.Getenv("SPOTOMUSIC_PROFILE")
+	}
+	return name
+}
+
+// youtubeAPIKey returns the API key used for read-only YouTube access
+func youtubeAPIKey() string {
+	return appConfig.YouTube.APIKey
+}
+
+// initConfig reads the config file given by --config or found in the
+// default locations, activates the profile and sets up logging. Flags take
+// precedence over environment variables, which override the file.
+func initConfig(cmd *cobra.Command) error {
+	cfg, err := config.Load(cfgFile, profileName(cmd))
+	if err != nil {
+		return err
+	}
+	appConfig = cfg
+
 	// Set up logging
-	logger.SetVerbose(viper.GetBool("verbose"))
-	
+	if err := logger.Configure(cfg.Logging.Level, cfg.Logging.Verbose); err != nil {
+		return err
+	}
+
 	logger.Info("Starting SpoToMusic...")
+	if cfg.Profile.Name != profile.Default {
+		logger.Infof("Using profile %s", cfg.Profile.Name)
+	}
+	return nil
 }
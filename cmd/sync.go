@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+	"spotomusic/internal/transfer"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync [spotify-playlist...]",
+	Short: "Brings the YouTube copies of playlists up to date with Spotify",
+	Long: `This command adds the tracks that are new in a Spotify playlist to the
+YouTube playlist it was transferred to. With --prune the videos a transfer
+added for tracks that were since removed from Spotify are deleted as well;
+videos added by hand are kept. Playlists that were never transferred are
+transferred in full.
+
+When the Spotify playlist was renamed, or its description template renders
+differently, the name and description of the YouTube playlist are updated
+too (disable with --metadata=false). The templates a playlist was created
+with keep applying unless the manifest or the flags set others. A template
+using .Description, .Owner or .Image is left alone while Spotify does not
+show that field, and .Date stays the day of the first transfer.
+
+The playlists are taken from --manifest, the arguments, or else every
+playlist in the transfer history. Manifest entries can set their own
+destination name, privacy, description template, sync mode, pruning and
+matching preferences.
+
+Sync modes:
+  append   new tracks are added to the end of the YouTube playlist
+  ordered  new tracks are inserted after the track they follow on Spotify
+  off      the playlist is transferred once and never synced
+
+Examples:
+  spotomusic sync
+  spotomusic sync 37i9dQZF1DXcBWIGoYBM5M --prune --dry-run
+  spotomusic sync --manifest playlists.yaml
+  spotomusic sync --manifest playlists.yaml --mode ordered
+  spotomusic sync --description-template "{{.Description}} Cover: {{.Image}}"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun := appConfig.Transfer.DryRun
+		mode, _ := cmd.Flags().GetString("mode")
+		syncMode, err := transfer.ParseSyncMode(mode)
+		if err != nil {
+			return err
+		}
+		prune, _ := cmd.Flags().GetBool("prune")
+		metadata, _ := cmd.Flags().GetBool("metadata")
+
+		reportPath, _ := cmd.Flags().GetString("report")
+		if reportPath != "" {
+			if err := transfer.ValidateReportPath(reportPath); err != nil {
+				return err
+			}
+		}
+
+		preferValue := appConfig.Matching.Prefer
+		if cmd.Flags().Changed("prefer") {
+			preferValue, _ = cmd.Flags().GetString("prefer")
+		}
+		prefer, err := transfer.ParsePreference(preferValue)
+		if err != nil {
+			return err
+		}
+
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+
+		var entries []transfer.ManifestEntry
+		manifestPath, _ := cmd.Flags().GetString("manifest")
+		switch {
+		case manifestPath != "":
+			if len(args) > 0 {
+				return fmt.Errorf("--manifest cannot be combined with playlist arguments")
+			}
+			manifest, err := loadManifest(cmd, manifestPath)
+			if err != nil {
+				return err
+			}
+			entries = manifest.Playlists
+		case len(args) > 0:
+			for _, arg := range args {
+				entries = append(entries, transfer.ManifestEntry{Source: arg, Sync: syncMode})
+			}
+		default:
+			playlistIDs, err := store.Mirrored()
+			if err != nil {
+				return err
+			}
+			if len(playlistIDs) == 0 {
+				fmt.Println("No transferred playlists recorded yet.")
+				return nil
+			}
+			for _, id := range playlistIDs {
+				entries = append(entries, transfer.ManifestEntry{Source: id, Sync: syncMode})
+			}
+		}
+
+		playlist, err := playlistOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Flags given on the command line override the manifest and the
+		// templates recorded for a mirror
+		for i := range entries {
+			if cmd.Flags().Changed("mode") {
+				entries[i].Sync = syncMode
+			}
+			if cmd.Flags().Changed("prune") || entries[i].Prune == nil {
+				entries[i].Prune = &prune
+			}
+			if cmd.Flags().Changed("metadata") || entries[i].Metadata == nil {
+				entries[i].Metadata = &metadata
+			}
+			if cmd.Flags().Changed("title-template") {
+				entries[i].Name = playlist.Title
+			}
+			if cmd.Flags().Changed("description-template") {
+				entries[i].Description = playlist.Description
+			}
+			if cmd.Flags().Changed("privacy") {
+				entries[i].Privacy = playlist.Privacy
+			}
+		}
+
+		transferService := transfer.NewService(appConfig, transfer.Options{
+			Match: transfer.MatchOptions{
+				Prefer:        prefer,
+				AllowChannels: appConfig.Matching.AllowChannels,
+				DenyChannels:  appConfig.Matching.DenyChannels,
+			},
+			Concurrency:       appConfig.Transfer.Concurrency,
+			RequestsPerSecond: appConfig.Transfer.RequestsPerSecond,
+			YouTubeAPIKey:     youtubeAPIKey(),
+			Playlist:          playlist,
+		})
+
+		ctx := cmd.Context()
+		run := history.Run{
+			ID:        transfer.NewRunID(),
+			StartedAt: time.Now().UTC(),
+			DryRun:    dryRun,
+			Command:   "sync",
+			Options:   transferService.Options(),
+		}
+		mirrorOf := func(playlistID string) (transfer.TransferResult, bool, error) {
+			mirror, err := store.Mirror(playlistID)
+			if errors.Is(err, history.ErrNotTransferred) {
+				return transfer.TransferResult{}, false, nil
+			}
+			return mirror, err == nil, err
+		}
+		results, err := transferService.SyncEntries(ctx, entries, mirrorOf, dryRun)
+
+		finishRun(ctx, run, results, reportPath)
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("sync interrupted")
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("manifest", "", "Sync the playlists listed in this manifest file")
+	syncCmd.Flags().String("mode", "append", "How new tracks are added: append, ordered or off")
+	syncCmd.Flags().Bool("prune", false, "Remove videos whose track is no longer in the Spotify playlist")
+	syncCmd.Flags().Bool("metadata", true, "Update the YouTube playlist name and description when the Spotify playlist changed")
+	syncCmd.Flags().String("prefer", "any", "Preferred upload type when matching: topic, official_video or any")
+	syncCmd.Flags().String("report", "", "Write a per-track report (.json, .csv, .md or .html)")
+	addPlaylistFlags(syncCmd)
+}
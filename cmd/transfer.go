@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"spotomusic/internal/config"
+	"spotomusic/internal/history"
 	"spotomusic/internal/transfer"
 )
 
@@ -12,43 +18,318 @@ var transferCmd = &cobra.Command{
 	Use:   "transfer [playlist-id]",
 	Short: "Transfers the specified playlist to YouTube",
 	Long: `This command transfers the specified Spotify playlist to YouTube.
+With --from youtube it copies a YouTube playlist to Spotify instead, and
+with --from snapshot:<file> it reads playlists from a 'spotomusic backup'.
+
+Title and description templates use Go template syntax with the fields
+.Name, .Description, .Owner, .TrackCount, .URL, .ID, .Image (the cover
+image URL) and .Date. .Owner, .Description and .Image are empty when
+Spotify does not show them. --youtube-playlist-name is not a template.
 
 Examples:
   spotomusic transfer 37i9dQZF1DXcBWIGoYBM5M --name "My Awesome Playlist"
+  spotomusic transfer 37i9dQZF1DXcBWIGoYBM5M --youtube-playlist-name "Road Trip" --privacy unlisted
+  spotomusic transfer --all --title-template "{{.Name}} (Spotify)" --description-template "{{.Name}} by {{.Owner}}, {{.TrackCount}} tracks on {{.Date}}: {{.URL}}"
   spotomusic transfer --all
-  spotomusic transfer --interactive`,
+  spotomusic transfer --interactive
+  spotomusic transfer --all --report migration.html
+  spotomusic transfer --retry-failed 20240101-120000-3f9a2c1e
+  spotomusic transfer --all --dry-run --plan-out plan.json
+  spotomusic transfer --from youtube PLxxxxxxxxxxxxxxxx --name "From YouTube"
+  spotomusic transfer 37i9dQZF1DXcBWIGoYBM5M --to m3u:playlist.m3u8
+  spotomusic transfer --all --from snapshot:backup.json
+  spotomusic transfer --manifest playlists.yaml`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		all, _ := cmd.Flags().GetBool("all")
 		interactive, _ := cmd.Flags().GetBool("interactive")
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		dryRun := appConfig.Transfer.DryRun
 		playlistName, _ := cmd.Flags().GetString("name")
+		from, _ := cmd.Flags().GetString("from")
+		snapshotPath := ""
+		if path, ok := strings.CutPrefix(from, "snapshot:"); ok && path != "" {
+			from, snapshotPath = "spotify", path
+		}
+		if from != "spotify" && from != "youtube" {
+			return fmt.Errorf("unknown source %q (expected spotify, youtube or snapshot:<file>)", from)
+		}
+		to, _ := cmd.Flags().GetString("to")
+		destination, err := transfer.ParseDestination(to)
+		if err != nil {
+			return err
+		}
+		export := ""
+		if destination.IsFile() {
+			export = destination.String()
+		}
+
+		prefer, err := transfer.ParsePreference(appConfig.Matching.Prefer)
+		if err != nil {
+			return err
+		}
+
+		// One-off --deny-channel entries extend the configured denylist
+		denyChannels, _ := cmd.Flags().GetStringSlice("deny-channel")
+		denyChannels = append(appConfig.Matching.DenyChannels, denyChannels...)
+
+		playlist, err := playlistOptions(cmd)
+		if err != nil {
+			return err
+		}
+		// --youtube-playlist-name names the single playlist transferred
+		if youtubeName, _ := cmd.Flags().GetString("youtube-playlist-name"); youtubeName != "" {
+			if from == "youtube" || all || cmd.Flags().Changed("manifest") || cmd.Flags().Changed("retry-failed") {
+				return fmt.Errorf("--youtube-playlist-name only applies to a single playlist transferred to YouTube")
+			}
+			playlist.Name = youtubeName
+		}
+
+		transferService := transfer.NewService(appConfig, transfer.Options{
+			Match: transfer.MatchOptions{
+				Prefer:        prefer,
+				AllowChannels: appConfig.Matching.AllowChannels,
+				DenyChannels:  denyChannels,
+			},
+			Concurrency:       appConfig.Transfer.Concurrency,
+			RequestsPerSecond: appConfig.Transfer.RequestsPerSecond,
+			YouTubeAPIKey:     youtubeAPIKey(),
+			Export:            export,
+			Playlist:          playlist,
+		})
 
-		transferService := transfer.NewService()
+		if snapshotPath != "" {
+			if err := transferService.UseSnapshot(snapshotPath); err != nil {
+				return err
+			}
+		}
+
+		reportPath, _ := cmd.Flags().GetString("report")
+		if reportPath != "" {
+			if err := transfer.ValidateReportPath(reportPath); err != nil {
+				return err
+			}
+		}
+
+		planPath, _ := cmd.Flags().GetString("plan-out")
+		if planPath != "" && !dryRun {
+			return fmt.Errorf("--plan-out requires --dry-run")
+		}
+
+		if destination.IsFile() && (from == "youtube" || planPath != "") {
+			return fmt.Errorf("--to %s cannot be combined with --from youtube or --plan-out", to)
+		}
 
-		if all {
-			return transferService.TransferAllPlaylists(dryRun)
+		if from == "youtube" && (all || interactive || planPath != "" || cmd.Flags().Changed("retry-failed")) {
+			return fmt.Errorf("--from youtube only supports a single playlist without --all, --interactive, --plan-out or --retry-failed")
 		}
 
-		if interactive {
-			return transferService.TransferInteractive(dryRun)
+		retryRunID, _ := cmd.Flags().GetString("retry-failed")
+
+		var manifest *transfer.Manifest
+		if manifestPath, _ := cmd.Flags().GetString("manifest"); manifestPath != "" {
+			if from == "youtube" || all || interactive || retryRunID != "" || len(args) > 0 {
+				return fmt.Errorf("--manifest cannot be combined with a playlist argument, --from youtube, --all, --interactive or --retry-failed")
+			}
+			if manifest, err = loadManifest(cmd, manifestPath); err != nil {
+				return err
+			}
 		}
 
-		if len(args) == 0 {
+		ctx := cmd.Context()
+		run := history.Run{
+			ID:        transfer.NewRunID(),
+			StartedAt: time.Now().UTC(),
+			DryRun:    dryRun,
+			Options:   transferService.Options(),
+		}
+		var results []transfer.TransferResult
+		switch {
+		case from == "youtube":
+			if len(args) == 0 {
+				return fmt.Errorf("YouTube playlist ID or URL required")
+			}
+			results, err = transferService.TransferToSpotify(ctx, args[0], playlistName, dryRun)
+		case retryRunID != "":
+			list, loadErr := transfer.LoadRetryList(retryRunID)
+			if loadErr != nil {
+				return loadErr
+			}
+			run.RetryOf = list.RunID
+			results, err = transferService.RetryFailed(ctx, list, dryRun)
+		case manifest != nil:
+			results, err = transferService.TransferManifest(ctx, manifest, dryRun)
+		case all:
+			results, err = transferService.TransferAllPlaylists(ctx, dryRun)
+		case interactive:
+			results, err = transferService.TransferInteractive(ctx, dryRun)
+		case len(args) == 0:
 			return fmt.Errorf("playlist ID required or use --all/--interactive flag")
+		default:
+			results, err = transferService.TransferPlaylist(ctx, args[0], playlistName, dryRun)
 		}
 
-		playlistID := args[0]
-		return transferService.TransferPlaylist(playlistID, playlistName, dryRun)
+		if destination.IsFile() && len(results) > 0 {
+			if exportErr := transfer.WriteExport(destination, results); exportErr != nil {
+				fmt.Printf("Warning: %v\n", exportErr)
+			} else {
+				fmt.Printf("Playlist exported to %s\n", destination.Path)
+			}
+		}
+
+		// The report and history are kept even if the plan cannot be saved
+		var planErr error
+		if dryRun && from == "spotify" && len(results) > 0 {
+			plan := transfer.NewPlan(transferService.Options(), results)
+			fmt.Println()
+			plan.Print(os.Stdout)
+			if planPath != "" {
+				if planErr = plan.WriteFile(planPath); planErr == nil {
+					fmt.Printf("Plan saved to %s (apply it with: spotomusic apply %s)\n", planPath, planPath)
+				}
+			}
+		}
+
+		finishRun(ctx, run, results, reportPath)
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("transfer interrupted")
+		}
+		if err == nil {
+			err = planErr
+		}
+		return err
 	},
 }
 
+// loadManifest reads a manifest file. A --prefer flag given on the command
+// line overrides the preferences in the file.
+func loadManifest(cmd *cobra.Command, path string) (*transfer.Manifest, error) {
+	manifest, err := transfer.LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Flags().Changed("prefer") {
+		prefer, _ := cmd.Flags().GetString("prefer")
+		for i := range manifest.Playlists {
+			manifest.Playlists[i].Match.Prefer = prefer
+		}
+	}
+	return manifest, nil
+}
+
+// playlistOptions returns the privacy and templates of created playlists
+// from the config, overridden by the flags given on the command line
+func playlistOptions(cmd *cobra.Command) (transfer.PlaylistOptions, error) {
+	options := transfer.PlaylistOptions{
+		Title:       appConfig.YouTube.TitleTemplate,
+		Description: appConfig.YouTube.DescriptionTemplate,
+		Privacy:     appConfig.YouTube.Privacy,
+	}
+	if cmd.Flags().Changed("title-template") {
+		options.Title, _ = cmd.Flags().GetString("title-template")
+	}
+	if cmd.Flags().Changed("description-template") {
+		options.Description, _ = cmd.Flags().GetString("description-template")
+	}
+	if cmd.Flags().Changed("privacy") {
+		options.Privacy, _ = cmd.Flags().GetString("privacy")
+	}
+	if err := options.Validate(); err != nil {
+		return transfer.PlaylistOptions{}, err
+	}
+	return options, nil
+}
+
+// addPlaylistFlags adds the flags read by playlistOptions
+func addPlaylistFlags(cmd *cobra.Command) {
+	cmd.Flags().String("privacy", "private", "Privacy of created YouTube playlists: public, unlisted or private")
+	cmd.Flags().String("title-template", "", "Template for the name of created playlists, e.g. \"{{.Name}} ({{.Owner}})\"")
+	cmd.Flags().String("description-template", "", "Template for the description of created playlists, e.g. \"{{.Name}}: {{.URL}}\"")
+}
+
+// finishRun writes the report, retry list and history record of a run.
+// Partial results are still worth keeping after Ctrl-C.
+func finishRun(ctx context.Context, run history.Run, results []transfer.TransferResult, reportPath string) {
+	if len(results) == 0 {
+		return
+	}
+
+	if reportPath != "" {
+		if reportErr := transfer.NewReport(results).WriteFile(reportPath); reportErr != nil {
+			fmt.Printf("Warning: %v\n", reportErr)
+		} else {
+			fmt.Printf("Report written to %s\n", reportPath)
+		}
+	}
+
+	// Keep whatever still failed so it can be retried later. Exports have
+	// no YouTube playlist to retry into.
+	if !run.DryRun && run.Options.Export == "" {
+		saveRetryList(run.ID, results)
+	}
+
+	run.FinishedAt = time.Now().UTC()
+	run.Interrupted = ctx.Err() != nil
+	run.Playlists = results
+	recordRun(run)
+}
+
+// saveRetryList stores the failed tracks of a run and tells the user how to
+// retry them
+func saveRetryList(runID string, results []transfer.TransferResult) {
+	list := transfer.NewRetryList(runID, results)
+	if list.TrackCount() == 0 {
+		return
+	}
+
+	path, err := list.Save()
+	if err != nil {
+		fmt.Printf("Warning: retry list kaydedilemedi: %v\n", err)
+		return
+	}
+	fmt.Printf("%d failed tracks saved to %s\n", list.TrackCount(), path)
+	fmt.Printf("Retry them with: spotomusic transfer --retry-failed %s\n", runID)
+}
+
+// recordRun stores a run in the transfer history
+func recordRun(run history.Run) {
+	store, err := history.Open()
+	if err == nil {
+		err = store.Save(run)
+	}
+	if err != nil {
+		fmt.Printf("Warning: run history kaydedilemedi: %v\n", err)
+		return
+	}
+	fmt.Printf("Run recorded as %s (see: spotomusic history show %s)\n", run.ID, run.ID)
+}
+
 func init() {
 	rootCmd.AddCommand(transferCmd)
 
 	transferCmd.Flags().Bool("all", false, "Transfer all playlists")
 	transferCmd.Flags().Bool("interactive", false, "Interactive mode - select playlists")
 	transferCmd.Flags().String("name", "", "Name of the Spotify playlist (required for single playlist transfer)")
-	transferCmd.Flags().String("youtube-playlist-name", "", "Name of the playlist to create on YouTube")
-	transferCmd.Flags().Bool("skip-existing", true, "Skip existing playlists")
+	transferCmd.Flags().String("from", "spotify", "Source: spotify or snapshot:<file> (to YouTube), or youtube (to Spotify)")
+	transferCmd.Flags().String("to", "youtube", "Destination: youtube, or a file as m3u:<path>, xspf:<path> or json:<path>")
+	transferCmd.Flags().String("youtube-playlist-name", "", "Name of the playlist to create on YouTube (single playlist only)")
+	addPlaylistFlags(transferCmd)
+	transferCmd.Flags().Bool("skip-existing", true, "Skip playlists that already exist on the destination instead of adding to them")
+	transferCmd.Flags().String("prefer", "any", "Preferred upload type when matching: topic, official_video or any")
+	transferCmd.Flags().StringSlice("deny-channel", nil, "Never pick videos from this channel ID or name pattern (repeatable)")
+	transferCmd.Flags().String("retry-failed", "", "Retry the failed tracks of a previous run (run ID or retry list file)")
+	transferCmd.Flags().String("manifest", "", "Transfer the playlists listed in this manifest file, each with its own settings")
+	transferCmd.Flags().String("plan-out", "", "With --dry-run, save the plan to this file for 'spotomusic apply'")
+	transferCmd.Flags().String("report", "", "Write a per-track report (.json, .csv, .md or .html)")
+	transferCmd.Flags().Int("concurrency", 4, "Number of tracks searched in parallel")
+	transferCmd.Flags().Float64("requests-per-second", 5, "Maximum YouTube API requests per second across all workers (0 = unlimited)")
+	transferCmd.Flags().Int("max-retries", 3, "Times a failed YouTube request is repeated on server errors and rate limits")
+	transferCmd.Flags().Int("retry-delay-ms", 1000, "Wait before the first retry in milliseconds, doubled after every retry")
+
+	config.BindFlag("matching.prefer", transferCmd.Flags().Lookup("prefer"))
+	config.BindFlag("transfer.concurrency", transferCmd.Flags().Lookup("concurrency"))
+	config.BindFlag("transfer.requests_per_second", transferCmd.Flags().Lookup("requests-per-second"))
+	config.BindFlag("transfer.skip_existing", transferCmd.Flags().Lookup("skip-existing"))
+	config.BindFlag("transfer.max_retries", transferCmd.Flags().Lookup("max-retries"))
+	config.BindFlag("transfer.retry_delay_ms", transferCmd.Flags().Lookup("retry-delay-ms"))
 }
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+	"spotomusic/internal/transfer"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo <run-id>",
+	Short: "Rolls back the YouTube changes of a transfer run",
+	Long: `This command removes exactly what a transfer run added to YouTube:
+playlists created by the run are deleted, and videos added to existing
+playlists are removed again. Use --dry-run to preview the deletions.
+
+Playlists that later runs changed are only deleted with --force. Only
+transfer runs can be undone: sync, verify and repair runs also removed
+or replaced videos, which undo cannot put back.
+
+Examples:
+  spotomusic undo 20240101-120000-3f9a2c1e --dry-run
+  spotomusic undo latest`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun := appConfig.Transfer.DryRun
+		force, _ := cmd.Flags().GetBool("force")
+
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+
+		run, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+		if run.DryRun {
+			return fmt.Errorf("run %s was a dry run, nothing to undo", run.ID)
+		}
+		// Removing what these runs added would not bring back the videos
+		// they removed or replaced
+		if run.Command != "" {
+			return fmt.Errorf("run %s was made by '%s'; only transfer runs can be undone", run.ID, run.Command)
+		}
+		if run.UndoneAt != nil && !force {
+			return fmt.Errorf("run %s was already undone at %s (use --force to try again)", run.ID, run.UndoneAt.Local().Format("2006-01-02 15:04:05"))
+		}
+
+		// Later runs keep using the playlists this run created
+		dependents, err := store.DependentRuns(run)
+		if err != nil {
+			return err
+		}
+		if len(dependents) > 0 && !force {
+			ids := make([]string, len(dependents))
+			for i, dependent := range dependents {
+				ids[i] = dependent.ID
+			}
+			return fmt.Errorf("later runs %s changed playlists created by run %s (use --force to delete them anyway)", strings.Join(ids, ", "), run.ID)
+		}
+
+		ctx := cmd.Context()
+		transferService := transfer.NewService(appConfig, run.Options)
+
+		failed := false
+		deletedPlaylists, removedItems := 0, 0
+		for _, playlist := range run.Playlists {
+			undo, err := transferService.UndoPlaylist(ctx, playlist, dryRun)
+			if err != nil {
+				fmt.Printf("Error undoing %s: %v\n", playlist.PlaylistName, err)
+				failed = true
+				if ctx.Err() != nil {
+					break
+				}
+				continue
+			}
+			for _, undoErr := range undo.Errors {
+				fmt.Printf("Error: %s\n", undoErr)
+				failed = true
+			}
+			if undo.DeletedPlaylist {
+				deletedPlaylists++
+			}
+			removedItems += undo.RemovedItems
+		}
+
+		verb := "Undid"
+		if dryRun {
+			verb = "[DRY RUN] Would undo"
+		}
+		fmt.Printf("\n%s run %s: %d playlists deleted, %d videos removed\n", verb, run.ID, deletedPlaylists, removedItems)
+
+		if dryRun {
+			return nil
+		}
+		if failed {
+			return fmt.Errorf("undo incomplete; run it again to retry the remaining items")
+		}
+
+		now := time.Now().UTC()
+		run.UndoneAt = &now
+		return store.Save(run)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().Bool("force", false, "Undo a run even if it was already undone or later runs changed the playlists it created")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"spotomusic/internal/history"
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/transfer"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify <spotify-playlist>",
+	Short: "Audits the YouTube copy of a playlist for bad matches",
+	Long: `This command rescores every video in the YouTube copy of a playlist
+against its Spotify track using title, channel and duration, and flags
+suspicious matches as well as deleted and private videos. With --fix the
+flagged videos are replaced in place by better candidates.
+
+Examples:
+  spotomusic verify 37i9dQZF1DXcBWIGoYBM5M
+  spotomusic verify 37i9dQZF1DXcBWIGoYBM5M --fix --dry-run
+  spotomusic verify 37i9dQZF1DXcBWIGoYBM5M --fix --prefer topic`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+		fix, _ := cmd.Flags().GetBool("fix")
+		dryRun := appConfig.Transfer.DryRun
+
+		preferValue := appConfig.Matching.Prefer
+		if cmd.Flags().Changed("prefer") {
+			preferValue, _ = cmd.Flags().GetString("prefer")
+		}
+		prefer, err := transfer.ParsePreference(preferValue)
+		if err != nil {
+			return err
+		}
+
+		playlistID := spotify.ParsePlaylistID(args[0])
+		store, err := history.Open()
+		if err != nil {
+			return err
+		}
+		mirror, err := store.Mirror(playlistID)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		transferService := maintenanceService(prefer)
+		run := history.Run{
+			ID:        transfer.NewRunID(),
+			StartedAt: time.Now().UTC(),
+			Command:   "verify",
+			Options:   transferService.Options(),
+		}
+
+		region, _ := cmd.Flags().GetString("region")
+		result, err := transferService.VerifyPlaylist(ctx, playlistID, mirror, transfer.VerifyOptions{
+			Fix:    fix,
+			DryRun: dryRun,
+			Region: region,
+		})
+		if err != nil && len(result.Checks) == 0 {
+			return err
+		}
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if encodeErr := encoder.Encode(result); encodeErr != nil {
+				return encodeErr
+			}
+		} else {
+			hint := ""
+			if !fix {
+				hint = "Replace them with: spotomusic verify " + result.SourcePlaylistID + " --fix"
+			}
+			printVerifyResult(result, hint)
+		}
+
+		// Record replacements so later diffs and verifies know the new videos
+		if replaced := result.ReplacementResults(mirror.YouTubePlaylist); len(replaced.Tracks) > 0 {
+			run.FinishedAt = time.Now().UTC()
+			run.Interrupted = ctx.Err() != nil
+			run.Playlists = []transfer.TransferResult{replaced}
+			recordRun(run)
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("verify interrupted")
+		}
+		return err
+	},
+}
+
+// maintenanceService creates the service used to check and repair YouTube
+// copies, with the configured matching options
+func maintenanceService(prefer transfer.Preference) *transfer.Service {
+	return transfer.NewService(appConfig, transfer.Options{
+		Match: transfer.MatchOptions{
+			Prefer:        prefer,
+			AllowChannels: appConfig.Matching.AllowChannels,
+			DenyChannels:  appConfig.Matching.DenyChannels,
+		},
+		RequestsPerSecond: appConfig.Transfer.RequestsPerSecond,
+		YouTubeAPIKey:     youtubeAPIKey(),
+	})
+}
+
+// printVerifyResult prints the flagged items of an audit. hint is printed
+// after them unless empty.
+func printVerifyResult(result transfer.VerifyResult, hint string) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	flagged := result.Flagged()
+	fmt.Printf("Checked %d videos in %s: %d flagged\n", len(result.Checks), result.YouTubePlaylistID, len(flagged))
+	if result.Unpaired > 0 {
+		fmt.Printf("%d Spotify tracks have no video (see: spotomusic diff %s)\n", result.Unpaired, result.SourcePlaylistID)
+	}
+	for _, item := range result.Orphaned {
+		fmt.Printf("%s #%d %s is unavailable and its Spotify track is unknown\n", red("✗"), item.Position+1, item.Video.Title)
+	}
+	if len(flagged) == 0 {
+		if len(result.Orphaned) == 0 {
+			fmt.Println(green("No suspicious or unavailable videos found."))
+		}
+		return
+	}
+
+	fmt.Println()
+	for _, check := range flagged {
+		mark := yellow("?")
+		if check.Verdict.Unavailable() {
+			mark = red("✗")
+		}
+		fmt.Printf("%s %3d. %s - %s -> %s [%s]\n", mark, check.Position, check.Track.Artist, check.Track.Name, check.Item.Video.Title, check.Verdict)
+		fmt.Printf("       %s\n", strings.Join(check.Reasons, "; "))
+		switch {
+		case check.ReplacementItemID != "":
+			fmt.Printf("       %s %s\n", green("replaced with"), check.Replacement.Title)
+		case check.Replacement != nil:
+			fmt.Printf("       would replace with %s\n", check.Replacement.Title)
+		case check.Error != "":
+			fmt.Printf("       %s\n", red(check.Error))
+		}
+	}
+
+	if hint != "" {
+		fmt.Printf("\n%s\n", hint)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().Bool("fix", false, "Replace flagged videos with better candidates at the same position")
+	verifyCmd.Flags().String("format", "text", "Output format: text or json")
+	verifyCmd.Flags().String("region", "", "Also flag videos blocked in this country (ISO code, e.g. TR)")
+	verifyCmd.Flags().String("prefer", "any", "Preferred upload type for replacements: topic, official_video or any")
+}
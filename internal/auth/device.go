@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrDeviceFlowUnsupported is returned by Device for providers without a
+// device authorization endpoint
+var ErrDeviceFlowUnsupported = errors.New("the provider does not support device login")
+
+// Flow obtains a token interactively
+type Flow interface {
+	Login(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error)
+}
+
+// Device runs the OAuth2 device authorization grant (RFC 8628): the user
+// enters a short code on another device while the CLI polls for the token.
+// It needs no browser and no callback server.
+type Device struct {
+	// ShowCode tells the user where to enter the code. By default the code
+	// and verification URL are printed to stdout.
+	ShowCode func(response *oauth2.DeviceAuthResponse)
+}
+
+// Login requests a device code and polls until the user approved or denied
+// it, or the code expired
+func (d Device) Login(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	if config.Endpoint.DeviceAuthURL == "" {
+		return nil, ErrDeviceFlowUnsupported
+	}
+
+	response, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("device code alınamadı: %v", err)
+	}
+
+	show := d.ShowCode
+	if show == nil {
+		show = printCode
+	}
+	show(response)
+
+	token, err := config.DeviceAccessToken(ctx, response)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, errors.New("device code expired before the login was approved")
+		}
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode != "" {
+			return nil, fmt.Errorf("device login failed: %s", retrieveErr.ErrorCode)
+		}
+		return nil, fmt.Errorf("device login failed: %v", err)
+	}
+	return token, nil
+}
+
+// printCode asks the user to enter the code at the verification URL
+func printCode(response *oauth2.DeviceAuthResponse) {
+	fmt.Printf("Başka bir cihazda %s adresini açın ve şu kodu girin: %s\n", response.VerificationURI, response.UserCode)
+	if response.VerificationURIComplete != "" {
+		fmt.Printf("(veya doğrudan: %s)\n", response.VerificationURIComplete)
+	}
+	if !response.Expiry.IsZero() {
+		fmt.Printf("The code expires in %s. Waiting for approval...\n", time.Until(response.Expiry).Round(time.Second))
+	}
+}
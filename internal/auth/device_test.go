@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// newDeviceProvider serves a device endpoint and a token endpoint that
+// answers authorization_pending once and then finish
+func newDeviceProvider(t *testing.T, finish string) *oauth2.Config {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			// Google spells the field verification_url
+			w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-EFGH","verification_url":"https://example.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			if r.Form.Get("device_code") != "dev" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			polls++
+			if polls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			if finish != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"` + finish + `"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Endpoint: oauth2.Endpoint{
+			DeviceAuthURL: server.URL + "/device",
+			TokenURL:      server.URL + "/token",
+		},
+	}
+}
+
+func TestDeviceLogin(t *testing.T) {
+	var shown *oauth2.DeviceAuthResponse
+	login := Device{ShowCode: func(r *oauth2.DeviceAuthResponse) { shown = r }}
+
+	token, err := login.Login(context.Background(), newDeviceProvider(t, ""))
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if token.RefreshToken != "refresh" {
+		t.Errorf("token = %+v", token)
+	}
+	if shown == nil || shown.UserCode != "ABCD-EFGH" || shown.VerificationURI != "https://example.com/device" {
+		t.Errorf("shown code = %+v", shown)
+	}
+}
+
+func TestDeviceLoginErrors(t *testing.T) {
+	login := Device{ShowCode: func(*oauth2.DeviceAuthResponse) {}}
+
+	_, err := login.Login(context.Background(), newDeviceProvider(t, "access_denied"))
+	if err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("Login() error = %v, want access_denied", err)
+	}
+
+	_, err = login.Login(context.Background(), &oauth2.Config{})
+	if err != ErrDeviceFlowUnsupported {
+		t.Errorf("Login() error = %v, want ErrDeviceFlowUnsupported", err)
+	}
+}
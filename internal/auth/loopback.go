@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultTimeout is how long a login waits for the browser callback
+const DefaultTimeout = 5 * time.Minute
+
+// ErrStateMismatch is returned when the callback carries a state the login
+// did not issue, e.g. a stale or forged redirect
+var ErrStateMismatch = errors.New("OAuth state mismatch")
+
+// Loopback runs the OAuth2 authorization code flow with PKCE against a
+// callback server on the loopback interface
+type Loopback struct {
+	// Addr is the callback listen address. The default "127.0.0.1:0" picks
+	// a free port; providers that require a registered redirect URI need a
+	// fixed one.
+	Addr string
+	// Path is the callback path, "/" by default
+	Path string
+	// Timeout bounds the whole login, DefaultTimeout by default
+	Timeout time.Duration
+	// OpenURL shows the authorization URL to the user. By default it is
+	// printed to stdout.
+	OpenURL func(authURL string)
+}
+
+// callbackResult is what the callback handler hands back to Login
+type callbackResult struct {
+	token *oauth2.Token
+	err   error
+}
+
+// Login sends the user to the provider and waits for the redirect back to
+// the loopback server. config is not modified; the redirect URL is derived
+// from the listening address.
+func (l Loopback) Login(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	addr, path, timeout := l.Addr, l.Path, l.Timeout
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	if path == "" {
+		path = "/"
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("callback server başlatılamadı: %v", err)
+	}
+
+	cfg := *config
+	cfg.RedirectURL = fmt.Sprintf("http://%s%s", listener.Addr(), path)
+
+	state, err := randomState()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	resultCh := make(chan callbackResult, 1)
+	var once sync.Once
+	finish := func(result callbackResult) {
+		once.Do(func() { resultCh <- result })
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+
+		if query.Get("state") != state {
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			finish(callbackResult{err: ErrStateMismatch})
+			return
+		}
+		if reason := query.Get("error"); reason != "" {
+			if description := query.Get("error_description"); description != "" {
+				reason += ": " + description
+			}
+			http.Error(w, "Authorization failed: "+reason, http.StatusForbidden)
+			finish(callbackResult{err: fmt.Errorf("authorization denied: %s", reason)})
+			return
+		}
+		code := query.Get("code")
+		if code == "" {
+			http.Error(w, "Authorization code not found", http.StatusBadRequest)
+			finish(callbackResult{err: errors.New("authorization code not found in callback")})
+			return
+		}
+
+		token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+		if err != nil {
+			http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
+			finish(callbackResult{err: fmt.Errorf("token exchange failed: %v", err)})
+			return
+		}
+
+		fmt.Fprintf(w, "Authentication completed! You can close this window.")
+		finish(callbackResult{token: token})
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer func() {
+		// Give the browser its response before the server goes away
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	authURL := cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+	open := l.OpenURL
+	if open == nil {
+		open = printURL
+	}
+	open(authURL)
+
+	select {
+	case result := <-resultCh:
+		return result.token, result.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("no login callback within %s", timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// printURL asks the user to open the authorization URL
+func printURL(authURL string) {
+	fmt.Printf("Lütfen aşağıdaki URL'yi tarayıcınızda açın:\n%s\n\n", authURL)
+}
+
+// randomState returns an unguessable OAuth state value
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("state oluşturulamadı: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
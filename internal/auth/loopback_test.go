@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeProvider is a minimal OAuth2 server: its token endpoint only accepts
+// the code "good-code" with the verifier matching the last challenge
+type fakeProvider struct {
+	server    *httptest.Server
+	challenge string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		if r.Form.Get("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		if oauth2.S256ChallengeFromVerifier(r.Form.Get("code_verifier")) != p.challenge {
+			http.Error(w, `{"error":"invalid_grant","error_description":"bad verifier"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeProvider) config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.server.URL + "/auth",
+			TokenURL: p.server.URL + "/token",
+		},
+	}
+}
+
+// browser plays the user: it follows the authorization URL back to the
+// redirect URI, letting rewrite change the callback query first
+func (p *fakeProvider) browser(t *testing.T, rewrite func(url.Values)) func(string) {
+	return func(authURL string) {
+		parsed, err := url.Parse(authURL)
+		if err != nil {
+			t.Errorf("invalid auth URL: %v", err)
+			return
+		}
+		query := parsed.Query()
+		if query.Get("code_challenge_method") != "S256" || query.Get("state") == "" {
+			t.Errorf("auth URL lacks PKCE or state: %s", authURL)
+		}
+		p.challenge = query.Get("code_challenge")
+
+		callback := url.Values{"code": {"good-code"}, "state": {query.Get("state")}}
+		if rewrite != nil {
+			rewrite(callback)
+		}
+		go func() {
+			resp, err := http.Get(query.Get("redirect_uri") + "?" + callback.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+}
+
+func TestLoopbackLogin(t *testing.T) {
+	p := newFakeProvider(t)
+	config := p.config()
+
+	var redirect string
+	open := p.browser(t, nil)
+	login := Loopback{Path: "/callback", OpenURL: func(authURL string) {
+		parsed, _ := url.Parse(authURL)
+		redirect = parsed.Query().Get("redirect_uri")
+		open(authURL)
+	}}
+
+	token, err := login.Login(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+		t.Errorf("token = %+v", token)
+	}
+	if !strings.HasPrefix(redirect, "http://127.0.0.1:") || !strings.HasSuffix(redirect, "/callback") || strings.HasSuffix(redirect, ":0/callback") {
+		t.Errorf("redirect URI = %q, want an ephemeral loopback port", redirect)
+	}
+	if config.RedirectURL != "" {
+		t.Errorf("config.RedirectURL = %q, Login must not modify the config", config.RedirectURL)
+	}
+}
+
+func TestLoopbackLoginErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite func(url.Values)
+		want    string
+	}{
+		{
+			name:    "state mismatch",
+			rewrite: func(v url.Values) { v.Set("state", "forged") },
+			want:    ErrStateMismatch.Error(),
+		},
+		{
+			name: "access denied",
+			rewrite: func(v url.Values) {
+				v.Del("code")
+				v.Set("error", "access_denied")
+			},
+			want: "authorization denied: access_denied",
+		},
+		{
+			name:    "exchange rejected",
+			rewrite: func(v url.Values) { v.Set("code", "bad-code") },
+			want:    "token exchange failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newFakeProvider(t)
+			login := Loopback{OpenURL: p.browser(t, tt.rewrite)}
+
+			_, err := login.Login(context.Background(), p.config())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Login() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoopbackLoginTimeout(t *testing.T) {
+	p := newFakeProvider(t)
+	login := Loopback{Timeout: 50 * time.Millisecond, OpenURL: func(string) {}}
+
+	_, err := login.Login(context.Background(), p.config())
+	if err == nil || !strings.Contains(err.Error(), "no login callback") {
+		t.Errorf("Login() error = %v, want a timeout", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := login.Login(ctx, p.config()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Login() error = %v, want context.Canceled", err)
+	}
+}
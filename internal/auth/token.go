@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"spotomusic/internal/secret"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNotLoggedIn is returned when no token is saved for a service
+var ErrNotLoggedIn = errors.New("not logged in")
+
+// Token is a saved OAuth2 token with the scopes it was granted
+type Token struct {
+	oauth2.Token
+	// Scopes is empty for tokens saved before scopes were recorded
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// SavedToken is a Token kept as JSON in a secret store
+type SavedToken struct {
+	Store secret.Store
+	Name  string
+}
+
+// Location describes where the token is kept
+func (f SavedToken) Location() string {
+	return f.Store.Location(f.Name)
+}
+
+// Load reads the saved token, returning ErrNotLoggedIn when there is none
+func (f SavedToken) Load() (*Token, error) {
+	data, err := f.Store.Get(f.Name)
+	if errors.Is(err, secret.ErrNotFound) {
+		return nil, ErrNotLoggedIn
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("token dosyası parse edilemedi: %v", err)
+	}
+	return &token, nil
+}
+
+// Save writes token with the scopes granted in the token response. Refresh
+// responses may leave the scopes out; the saved ones are kept then.
+func (f SavedToken) Save(token *oauth2.Token) error {
+	saved := Token{Token: *token, Scopes: grantedScopes(token)}
+	if len(saved.Scopes) == 0 {
+		if previous, err := f.Load(); err == nil {
+			saved.Scopes = previous.Scopes
+		}
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return f.Store.Set(f.Name, data)
+}
+
+// Remove deletes the saved token. Removing a missing token is not an error.
+func (f SavedToken) Remove() error {
+	return f.Store.Delete(f.Name)
+}
+
+// grantedScopes reads the space separated scope field of a token response
+func grantedScopes(token *oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	return strings.Fields(scope)
+}
+
+// TokenSource returns a source that refreshes token through config and saves
+// every new token to f, so a refreshed access token survives the run.
+// Refreshing must outlive request contexts, so ctx should not be cancelled
+// before the source is done.
+func (f SavedToken) TokenSource(ctx context.Context, config *oauth2.Config, token *oauth2.Token) oauth2.TokenSource {
+	return &persistingSource{
+		source: config.TokenSource(ctx, token),
+		file:   f,
+		last:   token.AccessToken,
+	}
+}
+
+// Refresh forces a token refresh and saves the new token
+func (f SavedToken) Refresh(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*oauth2.Token, error) {
+	if token.RefreshToken == "" {
+		return nil, errors.New("the saved token has no refresh token; log in again")
+	}
+	expired := *token
+	expired.Expiry = time.Now().Add(-time.Minute)
+	return f.TokenSource(ctx, config, &expired).Token()
+}
+
+// persistingSource saves tokens whenever the wrapped source hands out a new
+// access token
+type persistingSource struct {
+	source oauth2.TokenSource
+	file   SavedToken
+
+	mu   sync.Mutex
+	last string
+}
+
+func (s *persistingSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.last {
+		s.last = token.AccessToken
+		if err := s.file.Save(token); err != nil {
+			fmt.Printf("Warning: refreshed token kaydedilemedi: %v\n", err)
+		}
+	}
+	return token, nil
+}
+
+// Status describes a saved login
+type Status struct {
+	Service string
+	// Location is where the token is kept
+	Location string
+	// Account is the user or channel name, empty when it could not be
+	// looked up
+	Account string
+	Scopes  []string
+	// Expiry is when the access token expires; it is refreshed on use as
+	// long as Refreshable is set
+	Expiry      time.Time
+	Refreshable bool
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"spotomusic/internal/secret"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSavedTokenScopes(t *testing.T) {
+	file := SavedToken{Store: secret.FileStore{Dir: t.TempDir()}, Name: "token.json"}
+
+	if _, err := file.Load(); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("Load() error = %v, want ErrNotLoggedIn", err)
+	}
+
+	granted := (&oauth2.Token{AccessToken: "first"}).WithExtra(map[string]interface{}{"scope": "read write"})
+	if err := file.Save(granted); err != nil {
+		t.Fatal(err)
+	}
+	// Refresh responses may omit the scopes
+	if err := file.Save(&oauth2.Token{AccessToken: "second"}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := file.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken != "second" || !reflect.DeepEqual(saved.Scopes, []string{"read", "write"}) {
+		t.Errorf("saved = %+v", saved)
+	}
+
+	if err := file.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Remove(); err != nil {
+		t.Errorf("removing a missing token: %v", err)
+	}
+}
+
+func TestTokenSourcePersistsRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"renewed","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	config := &oauth2.Config{ClientID: "client", Endpoint: oauth2.Endpoint{TokenURL: server.URL}}
+	file := SavedToken{Store: secret.FileStore{Dir: t.TempDir()}, Name: "token.json"}
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+
+	token, err := file.TokenSource(context.Background(), config, expired).Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "renewed" {
+		t.Errorf("AccessToken = %q, want renewed", token.AccessToken)
+	}
+
+	saved, err := file.Load()
+	if err != nil {
+		t.Fatalf("refreshed token was not saved: %v", err)
+	}
+	// The refresh token is kept when the response does not rotate it
+	if saved.AccessToken != "renewed" || saved.RefreshToken != "refresh" {
+		t.Errorf("saved = %+v", saved)
+	}
+
+	if _, err := file.Refresh(context.Background(), config, &oauth2.Token{AccessToken: "x"}); err == nil {
+		t.Error("Refresh() without a refresh token should fail")
+	}
+}
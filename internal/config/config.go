@@ -4,23 +4,51 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"spotomusic/internal/logger"
+	"spotomusic/internal/profile"
+	"spotomusic/internal/secret"
+	"spotomusic/internal/youtube"
 )
 
 type Config struct {
 	Spotify   SpotifyConfig   `mapstructure:"spotify"`
 	YouTube   YouTubeConfig   `mapstructure:"youtube"`
 	Transfer  TransferConfig  `mapstructure:"transfer"`
+	Matching  MatchingConfig  `mapstructure:"matching"`
 	Logging   LoggingConfig   `mapstructure:"logging"`
+	Secrets   SecretsConfig   `mapstructure:"secrets"`
+
+	// File is the config file that was read, empty when none exists
+	File string `mapstructure:"-"`
+	// Profile is the profile activated by Load
+	Profile profile.Profile `mapstructure:"-"`
+
+	// loaded holds the values Load returned, so Save can tell which ones
+	// were changed since
+	loaded map[string]interface{}
 }
 
 type SpotifyConfig struct {
+	// CredentialsFile holds the client_id and client_secret of the app
+	CredentialsFile string `mapstructure:"credentials_file"`
+	// PlaylistLinks are used when SPOTIFY_PLAYLIST_LINKS is not set
+	PlaylistLinks []string `mapstructure:"playlist_links"`
 }
 
 type YouTubeConfig struct {
 	CredentialsFile string `mapstructure:"credentials_file"`
+	// APIKey allows read-only dry runs without an OAuth login
+	APIKey string `mapstructure:"api_key"`
+	// Privacy of created playlists: public, unlisted or private
+	Privacy string `mapstructure:"privacy"`
+	// TitleTemplate and DescriptionTemplate name and describe created
+	// playlists, e.g. "{{.Name}} by {{.Owner}}"; empty keeps the defaults
+	TitleTemplate       string `mapstructure:"title_template"`
+	DescriptionTemplate string `mapstructure:"description_template"`
 }
 
 type TransferConfig struct {
@@ -28,6 +56,23 @@ type TransferConfig struct {
 	RetryDelay     int  `mapstructure:"retry_delay_ms"`
 	SkipExisting   bool `mapstructure:"skip_existing"`
 	DryRun         bool `mapstructure:"dry_run"`
+	// Concurrency is the number of tracks searched in parallel
+	Concurrency       int     `mapstructure:"concurrency"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+}
+
+type MatchingConfig struct {
+	// Prefer is one of topic, official_video or any
+	Prefer string `mapstructure:"prefer"`
+	// AllowChannels and DenyChannels hold channel IDs or name patterns
+	AllowChannels []string `mapstructure:"allow_channels"`
+	DenyChannels  []string `mapstructure:"deny_channels"`
+}
+
+type SecretsConfig struct {
+	// Backend is file or encrypted; empty picks encrypted when
+	// SPOTOMUSIC_SECRET_KEY or SPOTOMUSIC_SECRET_PASSPHRASE is set
+	Backend string `mapstructure:"backend"`
 }
 
 type LoggingConfig struct {
@@ -35,137 +80,307 @@ type LoggingConfig struct {
 	Verbose bool  `mapstructure:"verbose"`
 }
 
-// Load loads configuration from file and environment variables
-func Load() (*Config, error) {
-	viper.SetConfigName("spotomusic")
-	viper.SetConfigType("yaml")
-	
-	// Add config paths
+// Dir returns the SpoToMusic data directory (~/.spotomusic), creating it
+// if needed
+func Dir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("home directory bulunamadı: %v", err)
+		return "", fmt.Errorf("home directory bulunamadı: %v", err)
 	}
-	
-	viper.AddConfigPath(".")
-	viper.AddConfigPath(homeDir)
-	viper.AddConfigPath(filepath.Join(homeDir, ".spotomusic"))
-	
-	// Set default values
+
+	dir := filepath.Join(homeDir, ".spotomusic")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("data directory oluşturulamadı: %v", err)
+	}
+	return dir, nil
+}
+
+// envBindings maps config keys to the environment variables that override
+// them. Flags bound in cmd take precedence over both.
+var envBindings = map[string][]string{
+	"youtube.credentials_file": {"YOUTUBE_CREDENTIALS_FILE"},
+	"youtube.api_key":          {"YOUTUBE_API_KEY"},
+	"youtube.privacy":          {"SPOTOMUSIC_PRIVACY"},
+	"transfer.max_retries":     {"SPOTOMUSIC_MAX_RETRIES"},
+	"transfer.retry_delay_ms":  {"SPOTOMUSIC_RETRY_DELAY_MS"},
+	"transfer.skip_existing":   {"SPOTOMUSIC_SKIP_EXISTING"},
+	"transfer.dry_run":         {"SPOTOMUSIC_DRY_RUN"},
+	"logging.level":            {"SPOTOMUSIC_LOG_LEVEL"},
+	"logging.verbose":          {"SPOTOMUSIC_VERBOSE"},
+	"secrets.backend":          {"SPOTOMUSIC_SECRETS_BACKEND"},
+}
+
+// Load reads the config file, environment variables and bound flags and
+// activates the named profile. path is the --config file; when empty the
+// first existing file of DefaultFiles is used, and defaults apply if there
+// is none.
+//
+// Named profiles are configured in the profiles section of the config file
+// with the same layout as the top level; their settings override the
+// top-level ones for this run. Credentials not configured are looked up in
+// the profile directory, so a profile also works without a config entry.
+func Load(path, profileName string) (*Config, error) {
+	p, err := profile.New(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		if path, err = findConfigFile(); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("config file bulunamadı: %v", err)
+	}
+
+	viper.SetConfigType("yaml")
 	setDefaults()
-	
-	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+	for key, names := range envBindings {
+		viper.BindEnv(append([]string{key}, names...)...)
+	}
+
+	if path != "" {
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
 			return nil, fmt.Errorf("config file okunamadı: %v", err)
 		}
+		logger.Debugf("Using config file %s", path)
+	} else {
 		// Config file not found, use defaults
-		logger.Info("Config file bulunamadı, default değerler kullanılıyor")
+		logger.Debug("Config file bulunamadı, default değerler kullanılıyor")
 	}
-	
-	// Bind environment variables
-	viper.AutomaticEnv()
-	
+
+	// A named profile takes profileOnlyKeys only from its own entry;
+	// everything else falls back to the top level
+	settings := viper.GetViper()
+	if p.Name != profile.Default {
+		settings = viper.Sub("profiles." + p.Name)
+		if settings == nil {
+			logger.Infof("Profile %s is not configured, using the files in %s", p.Name, p.Dir)
+			settings = viper.New()
+		}
+		if err := viper.MergeConfigMap(settings.AllSettings()); err != nil {
+			return nil, fmt.Errorf("profile %s okunamadı: %v", p.Name, err)
+		}
+	}
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("config unmarshal edilemedi: %v", err)
 	}
-	
-	// Override with environment variables
-	overrideWithEnv(&config)
-	
+	config.File = path
+
+	p.YouTubeCredentialsFile = settings.GetString("youtube.credentials_file")
+	p.SpotifyCredentialsFile = settings.GetString("spotify.credentials_file")
+	p.PlaylistLinks = settings.GetStringSlice("spotify.playlist_links")
+	if file := os.Getenv("YOUTUBE_CREDENTIALS_FILE"); file != "" {
+		p.YouTubeCredentialsFile = file
+	}
+	config.YouTube.CredentialsFile = p.YouTubeCredentialsFile
+	config.Spotify.CredentialsFile = p.SpotifyCredentialsFile
+	config.Spotify.PlaylistLinks = p.PlaylistLinks
+	if p.Secrets, err = secret.Open(config.Secrets.Backend, p.Dir); err != nil {
+		// Only commands that use secrets fail, so 'config set
+		// secrets.backend' can still repair the setting
+		p.Secrets = secret.Unavailable(p.Dir, err)
+	}
+
+	profile.Activate(p)
+	config.Profile = p
+	config.loaded = make(map[string]interface{})
+	for _, key := range Keys() {
+		config.loaded[key], _ = config.Get(key)
+	}
 	return &config, nil
 }
 
+// profileOnlyKeys are not inherited from the top level by named profiles,
+// so every profile has its own accounts
+var profileOnlyKeys = map[string]bool{
+	"youtube.credentials_file": true,
+	"spotify.credentials_file": true,
+	"spotify.playlist_links":   true,
+}
+
+// DefaultFiles returns the config files searched when --config is not
+// given, in order. Save writes to the second one unless a file was read.
+func DefaultFiles() ([]string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("home directory bulunamadı: %v", err)
+	}
+	return []string{
+		filepath.Join(homeDir, ".spotomusic.yaml"),
+		filepath.Join(homeDir, ".spotomusic", "spotomusic.yaml"),
+		"spotomusic.yaml",
+	}, nil
+}
+
+// findConfigFile returns the first existing default config file, or ""
+func findConfigFile() (string, error) {
+	files, err := DefaultFiles()
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", nil
+}
+
 // setDefaults sets default configuration values
 func setDefaults() {
 	// Spotify defaults - no defaults needed for public playlists
 	
-	// YouTube defaults
-	homeDir, _ := os.UserHomeDir()
-	viper.SetDefault("youtube.credentials_file", filepath.Join(homeDir, ".spotomusic_youtube_credentials.json"))
-	
+	// YouTube defaults: no credentials file, the OAuth client is read from
+	// the secret store
+	viper.SetDefault("youtube.privacy", "private")
+
 	// Transfer defaults
 	viper.SetDefault("transfer.max_retries", 3)
 	viper.SetDefault("transfer.retry_delay_ms", 1000)
 	viper.SetDefault("transfer.skip_existing", true)
 	viper.SetDefault("transfer.dry_run", false)
+	viper.SetDefault("transfer.concurrency", 4)
+	viper.SetDefault("transfer.requests_per_second", 5.0)
+
+	// Matching defaults
+	viper.SetDefault("matching.prefer", "any")
+	viper.SetDefault("matching.allow_channels", []string{})
+	viper.SetDefault("matching.deny_channels", []string{})
 	
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.verbose", false)
 }
 
-// overrideWithEnv overrides config with environment variables
-func overrideWithEnv(config *Config) {
-	// Spotify
-	// if username := os.Getenv("SPOTIFY_USERNAME"); username != "" {
-	// 	config.Spotify.Username = username
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	// Validate Spotify config
+	// if c.Spotify.Username == "" {
+	// 	return fmt.Errorf("SPOTIFY_USERNAME gerekli (public playlist sahibinin kullanıcı adı)")
 	// }
 	
-	// YouTube
-	if credentialsFile := os.Getenv("YOUTUBE_CREDENTIALS_FILE"); credentialsFile != "" {
-		config.YouTube.CredentialsFile = credentialsFile
+	// Validate YouTube config. Without a credentials file the OAuth client
+	// comes from the secret store.
+	if c.YouTube.CredentialsFile != "" {
+		if _, err := os.Stat(c.YouTube.CredentialsFile); os.IsNotExist(err) {
+			return fmt.Errorf("YouTube credentials file bulunamadı: %s", c.YouTube.CredentialsFile)
+		}
 	}
-	if credentialsJSON := os.Getenv("YOUTUBE_CREDENTIALS_JSON"); credentialsJSON != "" {
-		// Write to temp file
-		homeDir, _ := os.UserHomeDir()
-		tempFile := filepath.Join(homeDir, ".spotomusic_youtube_credentials.json")
-		os.WriteFile(tempFile, []byte(credentialsJSON), 0600)
-		config.YouTube.CredentialsFile = tempFile
+
+	if c.Spotify.CredentialsFile != "" {
+		if _, err := os.Stat(c.Spotify.CredentialsFile); os.IsNotExist(err) {
+			return fmt.Errorf("Spotify credentials file bulunamadı: %s", c.Spotify.CredentialsFile)
+		}
 	}
-	
-	// Transfer
-	if dryRun := os.Getenv("SPOTOMUSIC_DRY_RUN"); dryRun == "true" {
-		config.Transfer.DryRun = true
+	if _, err := youtube.ParsePrivacy(c.YouTube.Privacy); err != nil {
+		return fmt.Errorf("youtube.privacy: %v", err)
 	}
-	if skipExisting := os.Getenv("SPOTOMUSIC_SKIP_EXISTING"); skipExisting == "false" {
-		config.Transfer.SkipExisting = false
+
+	// Validate secrets config
+	switch c.Secrets.Backend {
+	case "", secret.BackendFile, secret.BackendEncrypted:
+	default:
+		return fmt.Errorf("secrets.backend must be file or encrypted: %s", c.Secrets.Backend)
 	}
-	
-	// Logging
-	if verbose := os.Getenv("SPOTOMUSIC_VERBOSE"); verbose == "true" {
-		config.Logging.Verbose = true
-		config.Logging.Level = "debug"
+
+	// Validate transfer config
+	if c.Transfer.Concurrency < 1 {
+		return fmt.Errorf("transfer.concurrency en az 1 olmalı: %d", c.Transfer.Concurrency)
+	}
+	if c.Transfer.RequestsPerSecond < 0 {
+		return fmt.Errorf("transfer.requests_per_second negatif olamaz: %v", c.Transfer.RequestsPerSecond)
 	}
-}
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	// Validate Spotify config
-	// if c.Spotify.Username == "" {
-	// 	return fmt.Errorf("SPOTIFY_USERNAME gerekli (public playlist sahibinin kullanıcı adı)")
-	// }
-	
-	// Validate YouTube config
-	if c.YouTube.CredentialsFile == "" {
-		return fmt.Errorf("YouTube credentials file gerekli")
+	// Validate logging config
+	if _, err := logrus.ParseLevel(c.Logging.Level); c.Logging.Level != "" && err != nil {
+		return fmt.Errorf("logging.level must be trace, debug, info, warn or error: %s", c.Logging.Level)
 	}
-	if _, err := os.Stat(c.YouTube.CredentialsFile); os.IsNotExist(err) {
-		return fmt.Errorf("YouTube credentials file bulunamadı: %s", c.YouTube.CredentialsFile)
+	if c.Transfer.MaxRetries < 0 || c.Transfer.RetryDelay < 0 {
+		return fmt.Errorf("transfer.max_retries and transfer.retry_delay_ms cannot be negative")
+	}
+
+	// Validate matching config
+	switch c.Matching.Prefer {
+	case "", "topic", "official_video", "any":
+	default:
+		return fmt.Errorf("matching.prefer must be topic, official_video or any: %s", c.Matching.Prefer)
 	}
 	
 	return nil
 }
 
-// Save saves the configuration to file
+// Save writes the settings to the file the configuration was read from, or
+// to $HOME/.spotomusic/spotomusic.yaml. The settings of a named profile go
+// to its profiles entry. Values that came from the environment or flags,
+// and for a named profile the ones inherited from the top level, are only
+// written when they were changed after Load. Other entries of the file are
+// kept, and File is set to the file written.
 func (c *Config) Save() error {
-	homeDir, err := os.UserHomeDir()
+	prefix := c.profilePrefix()
+
+	v, configFile, err := c.openFile()
 	if err != nil {
-		return fmt.Errorf("home directory bulunamadı: %v", err)
+		return err
 	}
-	
-	configDir := filepath.Join(homeDir, ".spotomusic")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("config directory oluşturulamadı: %v", err)
+	for _, key := range Keys() {
+		value, _ := c.Get(key)
+		if !c.changed(key, value) && !c.stored(key) {
+			continue
+		}
+		v.Set(prefix+key, value)
 	}
-	
-	configFile := filepath.Join(configDir, "spotomusic.yaml")
-	
-	// Set values in viper
-	viper.Set("spotify", c.Spotify)
-	viper.Set("youtube", c.YouTube)
-	viper.Set("transfer", c.Transfer)
-	viper.Set("logging", c.Logging)
-	
-	return viper.WriteConfigAs(configFile)
+	if err := v.WriteConfigAs(configFile); err != nil {
+		return err
+	}
+	c.File = configFile
+	return nil
+}
+
+// changed reports whether value differs from the one Load returned for key
+func (c *Config) changed(key string, value interface{}) bool {
+	loaded, ok := c.loaded[key]
+	return !ok || !reflect.DeepEqual(loaded, value)
+}
+
+// stored reports whether the effective value of key belongs in the file
+// Save writes to: the profile entry's own values for a named profile, the
+// file values and defaults otherwise
+func (c *Config) stored(key string) bool {
+	switch c.Source(key).Kind {
+	case "profile":
+		return true
+	case "file", "default":
+		return c.profilePrefix() == ""
+	}
+	return false
+}
+
+// openFile reads the config file on its own, without defaults, environment
+// variables or flags, so it can be written back
+func (c *Config) openFile() (*viper.Viper, string, error) {
+	configFile := c.File
+	if configFile == "" {
+		files, err := DefaultFiles()
+		if err != nil {
+			return nil, "", err
+		}
+		configFile = files[1]
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return nil, "", fmt.Errorf("config directory oluşturulamadı: %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	v.SetConfigType("yaml")
+	if _, err := os.Stat(configFile); err == nil {
+		if err := v.ReadInConfig(); err != nil {
+			return nil, "", fmt.Errorf("config file okunamadı: %v", err)
+		}
+	}
+	return v, configFile, nil
 }
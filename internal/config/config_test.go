@@ -0,0 +1,200 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SPOTOMUSIC_SECRET_KEY", "")
+	t.Setenv("SPOTOMUSIC_SECRET_PASSPHRASE", "")
+	t.Setenv("SPOTOMUSIC_DRY_RUN", "true")
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "custom.yaml")
+	content := `
+transfer:
+  max_retries: 7
+  skip_existing: false
+logging:
+  level: warn
+profiles:
+  brand:
+    spotify:
+      playlist_links: ["https://open.spotify.com/playlist/brand"]
+    transfer:
+      max_retries: 1
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(filepath.Join(home, "missing.yaml"), ""); err == nil {
+		t.Error("Load() of a missing --config file should fail")
+	}
+
+	cfg, err := Load(path, "brand")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.File != path {
+		t.Errorf("File = %q, want %q", cfg.File, path)
+	}
+	// The profile overrides the file, the environment the defaults
+	if cfg.Transfer.MaxRetries != 1 || cfg.Transfer.SkipExisting || !cfg.Transfer.DryRun {
+		t.Errorf("Transfer = %+v", cfg.Transfer)
+	}
+	if cfg.Transfer.RetryDelay != 1000 || cfg.Logging.Level != "warn" {
+		t.Errorf("RetryDelay = %d, Level = %q", cfg.Transfer.RetryDelay, cfg.Logging.Level)
+	}
+	if cfg.Profile.Name != "brand" || len(cfg.Profile.PlaylistLinks) != 1 {
+		t.Errorf("Profile = %+v", cfg.Profile)
+	}
+}
+
+func TestLoadWithoutSecretKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SPOTOMUSIC_SECRET_KEY", "")
+	t.Setenv("SPOTOMUSIC_SECRET_PASSPHRASE", "")
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("secrets:\n  backend: encrypted\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load() should not need the secret key: %v", err)
+	}
+	if _, err := cfg.Profile.Secrets.Get("token.json"); err == nil {
+		t.Error("Secrets.Get() should report the missing key")
+	}
+}
+
+func TestSetAndSource(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SPOTOMUSIC_SECRET_KEY", "")
+	t.Setenv("SPOTOMUSIC_SECRET_PASSPHRASE", "")
+	t.Setenv("SPOTOMUSIC_MAX_RETRIES", "9")
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "spotomusic.yaml")
+	if err := os.WriteFile(path, []byte("matching:\n  prefer: topic\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := map[string]string{
+		"matching.prefer":      "file " + path,
+		"transfer.max_retries": "env SPOTOMUSIC_MAX_RETRIES",
+		"transfer.concurrency": "default",
+	}
+	for key, want := range sources {
+		if got := cfg.Source(key).String(); got != want {
+			t.Errorf("Source(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	if _, err := cfg.Get("transfer.nope"); err == nil {
+		t.Error("Get() of an unknown key should fail")
+	}
+	if _, err := cfg.Set("transfer.concurrency", "many"); err == nil {
+		t.Error("Set() of a non-number should fail")
+	}
+	if _, err := cfg.Set("matching.prefer", "loudest"); err == nil || cfg.Matching.Prefer != "topic" {
+		t.Errorf("Set() of an invalid preference: err = %v, prefer = %q", err, cfg.Matching.Prefer)
+	}
+	if _, err := cfg.Set("matching.deny_channels", "a, b,"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the file content is written back, not defaults or the environment
+	written := viper.New()
+	written.SetConfigFile(path)
+	if err := written.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if got := written.GetStringSlice("matching.deny_channels"); len(got) != 2 || got[1] != "b" {
+		t.Errorf("deny_channels = %v", got)
+	}
+	if written.GetString("matching.prefer") != "topic" || written.IsSet("transfer.max_retries") {
+		t.Errorf("written settings = %v", written.AllSettings())
+	}
+}
+
+func TestSaveSkipsEnvironmentAndInheritedValues(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SPOTOMUSIC_SECRET_KEY", "")
+	t.Setenv("SPOTOMUSIC_SECRET_PASSPHRASE", "")
+	t.Setenv("YOUTUBE_API_KEY", "secret-api-key")
+	t.Setenv("SPOTOMUSIC_MAX_RETRIES", "9")
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "spotomusic.yaml")
+	content := `
+matching:
+  prefer: topic
+profiles:
+  brand:
+    transfer:
+      concurrency: 2
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	read := func() *viper.Viper {
+		t.Helper()
+		written := viper.New()
+		written.SetConfigFile(path)
+		if err := written.ReadInConfig(); err != nil {
+			t.Fatal(err)
+		}
+		return written
+	}
+
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Logging.Level = "debug"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	written := read()
+	if written.IsSet("youtube.api_key") || written.IsSet("transfer.max_retries") {
+		t.Errorf("environment values were written: %v", written.AllSettings())
+	}
+	if written.GetString("logging.level") != "debug" || written.GetString("matching.prefer") != "topic" {
+		t.Errorf("written settings = %v", written.AllSettings())
+	}
+
+	viper.Reset()
+	cfg, err = Load(path, "brand")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Transfer.RequestsPerSecond = 2
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	written = read()
+	profile := written.Sub("profiles.brand")
+	if profile == nil || profile.GetInt("transfer.concurrency") != 2 || profile.GetFloat64("transfer.requests_per_second") != 2 {
+		t.Fatalf("profile settings = %v", written.AllSettings())
+	}
+	if profile.IsSet("matching.prefer") || profile.IsSet("logging.level") || profile.IsSet("youtube.api_key") {
+		t.Errorf("inherited values were copied into the profile: %v", profile.AllSettings())
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"spotomusic/internal/profile"
+)
+
+// Source tells where the effective value of a setting came from
+type Source struct {
+	// Kind is flag, env, profile, file or default
+	Kind string
+	// Detail names the flag, environment variable, profile or file
+	Detail string
+}
+
+func (s Source) String() string {
+	if s.Detail == "" {
+		return s.Kind
+	}
+	return s.Kind + " " + s.Detail
+}
+
+// boundFlags are the flags registered with BindFlag, by config key
+var boundFlags = map[string]*pflag.Flag{}
+
+// BindFlag binds a command-line flag to a config key. A flag that was set
+// overrides every other source.
+func BindFlag(key string, flag *pflag.Flag) error {
+	boundFlags[key] = flag
+	return viper.BindPFlag(key, flag)
+}
+
+// Keys returns every config key in the order of the config file, e.g.
+// "transfer.max_retries"
+func Keys() []string {
+	var keys []string
+	config := reflect.TypeOf(Config{})
+	for i := 0; i < config.NumField(); i++ {
+		section, ok := tag(config.Field(i))
+		if !ok || config.Field(i).Type.Kind() != reflect.Struct {
+			continue
+		}
+		fields := config.Field(i).Type
+		for j := 0; j < fields.NumField(); j++ {
+			if name, ok := tag(fields.Field(j)); ok {
+				keys = append(keys, section+"."+name)
+			}
+		}
+	}
+	return keys
+}
+
+// tag returns the config name of a struct field
+func tag(field reflect.StructField) (string, bool) {
+	name := field.Tag.Get("mapstructure")
+	return name, name != "" && name != "-"
+}
+
+// field returns the settable struct field of key
+func (c *Config) field(key string) (reflect.Value, error) {
+	parts := strings.Split(key, ".")
+	value := reflect.ValueOf(c).Elem()
+	for _, part := range parts {
+		if value.Kind() != reflect.Struct {
+			value = reflect.Value{}
+			break
+		}
+		if value = fieldByTag(value, part); !value.IsValid() {
+			break
+		}
+	}
+	if len(parts) != 2 || !value.IsValid() || value.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("unknown config key %q (see: spotomusic config show)", key)
+	}
+	return value, nil
+}
+
+// fieldByTag returns the field of a struct with the config name, or the
+// zero Value
+func fieldByTag(value reflect.Value, name string) reflect.Value {
+	for i := 0; i < value.NumField(); i++ {
+		if fieldName, ok := tag(value.Type().Field(i)); ok && fieldName == name {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// Get returns the effective value of key
+func (c *Config) Get(key string) (interface{}, error) {
+	value, err := c.field(key)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// Source reports where the effective value of key came from
+func (c *Config) Source(key string) Source {
+	if flag := boundFlags[key]; flag != nil && flag.Changed {
+		return Source{Kind: "flag", Detail: "--" + flag.Name}
+	}
+	for _, name := range envBindings[key] {
+		if os.Getenv(name) != "" {
+			return Source{Kind: "env", Detail: name}
+		}
+	}
+	named := c.profilePrefix() != ""
+	if named && viper.InConfig(c.profilePrefix()+key) {
+		return Source{Kind: "profile", Detail: c.Profile.Name}
+	}
+	// The profile entry was merged into the file settings above, so the
+	// file only counts for keys a profile inherits
+	if c.File != "" && viper.InConfig(key) && !(named && profileOnlyKeys[key]) {
+		return Source{Kind: "file", Detail: c.File}
+	}
+	return Source{Kind: "default"}
+}
+
+// Set parses value for key, stores it in the config file and returns the
+// file written. For a named profile the value goes to its profiles entry.
+// Lists are given comma separated.
+func (c *Config) Set(key, value string) (string, error) {
+	field, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed interface{}
+	switch field.Kind() {
+	case reflect.String:
+		parsed = value
+	case reflect.Bool:
+		parsed, err = strconv.ParseBool(value)
+	case reflect.Int:
+		parsed, err = strconv.Atoi(value)
+	case reflect.Float64:
+		parsed, err = strconv.ParseFloat(value, 64)
+	case reflect.Slice:
+		parsed = ParseList(value)
+	default:
+		err = fmt.Errorf("unsupported type %s", field.Kind())
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+
+	// Reject values that would make a valid configuration invalid
+	previous := *c
+	field.Set(reflect.ValueOf(parsed))
+	if err := c.Validate(); err != nil && previous.Validate() == nil {
+		*c = previous
+		return "", err
+	}
+
+	prefix := c.profilePrefix()
+	v, configFile, err := c.openFile()
+	if err != nil {
+		return "", err
+	}
+	v.Set(prefix+key, parsed)
+	return configFile, v.WriteConfigAs(configFile)
+}
+
+// ParseList splits a comma separated list, dropping empty items
+func ParseList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// profilePrefix returns the key prefix of the profiles entry of a named
+// profile, or "" for the default profile
+func (c *Config) profilePrefix() string {
+	if c.Profile.Name == "" || c.Profile.Name == profile.Default {
+		return ""
+	}
+	return "profiles." + c.Profile.Name + "."
+}
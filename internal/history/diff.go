@@ -0,0 +1,125 @@
+package history
+
+import (
+	"strings"
+
+	"spotomusic/internal/transfer"
+)
+
+// TrackChange describes how the outcome of one track differs between runs
+type TrackChange struct {
+	SourcePlaylistID string                `json:"source_playlist_id"`
+	Artist           string                `json:"artist"`
+	Name             string                `json:"name"`
+	Before           *transfer.TrackResult `json:"before,omitempty"`
+	After            *transfer.TrackResult `json:"after,omitempty"`
+}
+
+// Kind summarises the change in a single word
+func (c TrackChange) Kind() string {
+	switch {
+	case c.Before == nil:
+		return "added"
+	case c.After == nil:
+		return "removed"
+	case c.Before.Failed() && !c.After.Failed():
+		return "fixed"
+	case !c.Before.Failed() && c.After.Failed():
+		return "regressed"
+	case c.Before.Status != c.After.Status:
+		return "status"
+	default:
+		return "video"
+	}
+}
+
+// RunDiff compares the per-track outcomes of two runs
+type RunDiff struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Changes []TrackChange `json:"changes"`
+}
+
+// Diff compares two runs track by track. Tracks are identified by their
+// source playlist, artist and name, so reordering is not a change.
+func Diff(from, to Run) RunDiff {
+	diff := RunDiff{From: from.ID, To: to.ID}
+
+	before := indexTracks(from)
+	after := indexTracks(to)
+
+	for _, key := range orderedKeys(from, to) {
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+
+		change := TrackChange{SourcePlaylistID: key.playlist}
+		switch {
+		case inBefore && inAfter:
+			if b.Status == a.Status && videoID(b) == videoID(a) {
+				continue
+			}
+			change.Before, change.After = &b, &a
+			change.Artist, change.Name = a.Track.Artist, a.Track.Name
+		case inAfter:
+			change.After = &a
+			change.Artist, change.Name = a.Track.Artist, a.Track.Name
+		default:
+			change.Before = &b
+			change.Artist, change.Name = b.Track.Artist, b.Track.Name
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	return diff
+}
+
+type trackKey struct {
+	playlist string
+	track    string
+}
+
+func keyFor(playlist transfer.TransferResult, track transfer.TrackResult) trackKey {
+	return trackKey{
+		playlist: playlist.SourcePlaylistID,
+		track:    strings.ToLower(track.Track.Artist + "\x00" + track.Track.Name),
+	}
+}
+
+func indexTracks(run Run) map[trackKey]transfer.TrackResult {
+	index := make(map[trackKey]transfer.TrackResult)
+	for _, playlist := range run.Playlists {
+		for _, track := range playlist.Tracks {
+			index[keyFor(playlist, track)] = track
+		}
+	}
+	return index
+}
+
+// orderedKeys lists every track of both runs, in the order they appear
+func orderedKeys(runs ...Run) []trackKey {
+	seen := make(map[trackKey]bool)
+	var keys []trackKey
+	for _, run := range runs {
+		for _, playlist := range run.Playlists {
+			for _, track := range playlist.Tracks {
+				key := keyFor(playlist, track)
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// videoID identifies the destination item a track was matched to
+func videoID(track transfer.TrackResult) string {
+	if track.SpotifyTrack != nil {
+		return track.SpotifyTrack.ID
+	}
+	if track.Video == nil {
+		return ""
+	}
+	return track.Video.ID
+}
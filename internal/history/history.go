@@ -0,0 +1,271 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"spotomusic/internal/profile"
+	"spotomusic/internal/transfer"
+)
+
+// ErrNotTransferred is returned for source playlists without a recorded
+// transfer
+var ErrNotTransferred = errors.New("no recorded transfer")
+
+// Run is the persisted record of a single transfer invocation
+type Run struct {
+	ID          string    `json:"id"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	DryRun      bool      `json:"dry_run"`
+	Interrupted bool      `json:"interrupted"`
+	// Command names the maintenance command that made the run; empty for
+	// transfers
+	Command string `json:"command,omitempty"`
+	// RetryOf is the run whose failed tracks this run retried
+	RetryOf string `json:"retry_of,omitempty"`
+	// UndoneAt is set once the run has been rolled back
+	UndoneAt  *time.Time                `json:"undone_at,omitempty"`
+	Options   transfer.Options          `json:"options"`
+	Playlists []transfer.TransferResult `json:"playlists"`
+}
+
+// Totals returns the summed track counts of all playlists in the run
+func (r Run) Totals() (total, matched, failed int) {
+	for _, playlist := range r.Playlists {
+		total += playlist.TotalTracks
+		matched += playlist.MatchedTracks
+		failed += playlist.FailedTracks
+	}
+	return total, matched, failed
+}
+
+// Store keeps one JSON document per run in a directory
+type Store struct {
+	dir string
+}
+
+// Open returns the history store of the active profile, see
+// profile.Profile.DataDir
+func Open() (*Store, error) {
+	dir, err := profile.Active().DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return OpenDir(filepath.Join(dir, "history"))
+}
+
+// OpenDir returns a history store rooted at dir
+func OpenDir(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("history directory oluşturulamadı: %v", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save writes a run, replacing any earlier record with the same ID
+func (s *Store) Save(run Run) error {
+	if run.ID == "" {
+		return fmt.Errorf("run ID gerekli")
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn record
+	tmp := s.path(run.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("run kaydedilemedi: %v", err)
+	}
+	return os.Rename(tmp, s.path(run.ID))
+}
+
+// Load reads a run by ID. "latest" resolves to the most recent run.
+func (s *Store) Load(id string) (Run, error) {
+	if id == "latest" {
+		runs, err := s.List()
+		if err != nil {
+			return Run{}, err
+		}
+		if len(runs) == 0 {
+			return Run{}, fmt.Errorf("no transfer runs recorded yet")
+		}
+		return runs[0], nil
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Run{}, fmt.Errorf("run bulunamadı: %s", id)
+		}
+		return Run{}, err
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return Run{}, fmt.Errorf("run parse edilemedi (%s): %v", id, err)
+	}
+	return run, nil
+}
+
+// List returns all runs, newest first
+func (s *Store) List() ([]Run, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("history okunamadı: %v", err)
+	}
+
+	var runs []Run
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		run, err := s.Load(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			// Skip unreadable records instead of hiding the whole history
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// LatestFor returns the most recent non-dry run that transferred the given
+// source playlist into a YouTube playlist
+func (s *Store) LatestFor(sourcePlaylistID string) (Run, *transfer.TransferResult, error) {
+	runs, err := s.List()
+	if err != nil {
+		return Run{}, nil, err
+	}
+	for _, run := range runs {
+		if run.DryRun || run.UndoneAt != nil {
+			continue
+		}
+		for i := range run.Playlists {
+			if mirrors(run.Playlists[i], sourcePlaylistID) {
+				return run, &run.Playlists[i], nil
+			}
+		}
+	}
+	return Run{}, nil, fmt.Errorf("%w for playlist %s", ErrNotTransferred, sourcePlaylistID)
+}
+
+// Mirror returns the YouTube playlist a source playlist was last transferred
+// to, with the newest recorded outcome of every track across all runs into
+// that playlist. Retry runs only carry the retried tracks, so a single run
+// is not enough to know where every track went.
+func (s *Store) Mirror(sourcePlaylistID string) (transfer.TransferResult, error) {
+	_, latest, err := s.LatestFor(sourcePlaylistID)
+	if err != nil {
+		return transfer.TransferResult{}, err
+	}
+
+	runs, err := s.List()
+	if err != nil {
+		return transfer.TransferResult{}, err
+	}
+
+	mirror := *latest
+	mirror.Tracks = nil
+	seen := make(map[string]bool)
+	for _, run := range runs {
+		if run.DryRun || run.UndoneAt != nil {
+			continue
+		}
+		for _, playlist := range run.Playlists {
+			if !mirrors(playlist, sourcePlaylistID) || playlist.YouTubePlaylist.ID != latest.YouTubePlaylist.ID {
+				continue
+			}
+			for _, track := range playlist.Tracks {
+				key := strings.ToLower(track.Track.Artist + "\x00" + track.Track.Name)
+				if !seen[key] && !track.Failed() {
+					seen[key] = true
+					mirror.Tracks = append(mirror.Tracks, track)
+				}
+			}
+		}
+	}
+	return mirror, nil
+}
+
+// Mirrored returns the source playlists that have a YouTube copy, most
+// recently transferred first
+func (s *Store) Mirrored() ([]string, error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, run := range runs {
+		if run.DryRun || run.UndoneAt != nil {
+			continue
+		}
+		for _, playlist := range run.Playlists {
+			id := playlist.SourcePlaylistID
+			if !seen[id] && mirrors(playlist, id) {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// DependentRuns returns the later runs, not undone and not dry, that
+// changed a playlist the given run created. Deleting the playlist would
+// leave them pointing at nothing.
+func (s *Store) DependentRuns(run Run) ([]Run, error) {
+	created := make(map[string]bool)
+	for _, playlist := range run.Playlists {
+		if playlist.CreatedPlaylist && playlist.YouTubePlaylist != nil && playlist.YouTubePlaylist.ID != "" {
+			created[playlist.YouTubePlaylist.ID] = true
+		}
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var dependents []Run
+	for _, later := range runs {
+		if later.ID == run.ID || later.DryRun || later.UndoneAt != nil || !later.StartedAt.After(run.StartedAt) {
+			continue
+		}
+		for _, playlist := range later.Playlists {
+			if playlist.YouTubePlaylist != nil && created[playlist.YouTubePlaylist.ID] {
+				dependents = append(dependents, later)
+				break
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// mirrors reports whether a result copied the source playlist to YouTube
+func mirrors(result transfer.TransferResult, sourcePlaylistID string) bool {
+	return result.SourcePlaylistID == sourcePlaylistID &&
+		result.Direction == transfer.DirectionToYouTube &&
+		result.YouTubePlaylist != nil && result.YouTubePlaylist.ID != ""
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
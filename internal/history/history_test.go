@@ -0,0 +1,207 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/transfer"
+	"spotomusic/internal/youtube"
+)
+
+func matched(position int, name, videoID string) transfer.TrackResult {
+	return transfer.TrackResult{
+		Position: position,
+		Track:    spotify.Track{Artist: "Artist", Name: name},
+		Video:    &youtube.YouTubeVideo{ID: videoID, Title: name},
+		Status:   transfer.StatusMatched,
+	}
+}
+
+func failed(position int, name string) transfer.TrackResult {
+	return transfer.TrackResult{
+		Position: position,
+		Track:    spotify.Track{Artist: "Artist", Name: name},
+		Status:   transfer.StatusNoGoodMatch,
+	}
+}
+
+func TestStoreSaveLoadList(t *testing.T) {
+	store, err := OpenDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenDir() error = %v", err)
+	}
+
+	older := Run{ID: "20240101-120000", StartedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	newer := Run{
+		ID:        "20240201-120000",
+		StartedAt: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		Playlists: []transfer.TransferResult{
+			{SourcePlaylistID: "src", TotalTracks: 2, MatchedTracks: 1, FailedTracks: 1},
+		},
+	}
+	for _, run := range []Run{older, newer} {
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != newer.ID {
+		t.Fatalf("Expected newest run first, got %+v", runs)
+	}
+
+	latest, err := store.Load("latest")
+	if err != nil || latest.ID != newer.ID {
+		t.Errorf("Load(latest) = %v, %v", latest.ID, err)
+	}
+
+	total, matched, failed := latest.Totals()
+	if total != 2 || matched != 1 || failed != 1 {
+		t.Errorf("Totals() = %d, %d, %d", total, matched, failed)
+	}
+
+	if _, err := store.Load("missing"); err == nil {
+		t.Error("Expected error for unknown run")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := Run{ID: "a", Playlists: []transfer.TransferResult{{
+		SourcePlaylistID: "src",
+		Tracks: []transfer.TrackResult{
+			matched(1, "Same", "v1"),
+			failed(2, "Fixed"),
+			matched(3, "Regressed", "v3"),
+			matched(4, "Changed", "v4"),
+			matched(5, "Removed", "v5"),
+		},
+	}}}
+	to := Run{ID: "b", Playlists: []transfer.TransferResult{{
+		SourcePlaylistID: "src",
+		Tracks: []transfer.TrackResult{
+			matched(1, "Same", "v1"),
+			matched(2, "Fixed", "v2"),
+			failed(3, "Regressed"),
+			matched(4, "Changed", "v4b"),
+			matched(5, "Added", "v6"),
+		},
+	}}}
+
+	diff := Diff(from, to)
+
+	kinds := make(map[string]string)
+	for _, change := range diff.Changes {
+		kinds[change.Name] = change.Kind()
+	}
+	expected := map[string]string{
+		"Fixed":     "fixed",
+		"Regressed": "regressed",
+		"Changed":   "video",
+		"Removed":   "removed",
+		"Added":     "added",
+	}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v", len(expected), kinds)
+	}
+	for name, kind := range expected {
+		if kinds[name] != kind {
+			t.Errorf("Change for %s = %q, want %q", name, kinds[name], kind)
+		}
+	}
+}
+
+func TestMirrorMergesRetries(t *testing.T) {
+	store, err := OpenDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenDir() error = %v", err)
+	}
+
+	playlist := &youtube.YouTubePlaylist{ID: "PL1"}
+	runs := []Run{
+		{ID: "1", StartedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Playlists: []transfer.TransferResult{{
+			SourcePlaylistID: "src",
+			YouTubePlaylist:  playlist,
+			Tracks:           []transfer.TrackResult{matched(1, "One", "v1"), failed(2, "Two")},
+		}}},
+		{ID: "2", StartedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), RetryOf: "1", Playlists: []transfer.TransferResult{{
+			SourcePlaylistID: "src",
+			YouTubePlaylist:  playlist,
+			Tracks:           []transfer.TrackResult{matched(1, "Two", "v2")},
+		}}},
+		// Dry runs and exports never produced a YouTube playlist
+		{ID: "3", StartedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), DryRun: true, Playlists: []transfer.TransferResult{{
+			SourcePlaylistID: "src",
+			YouTubePlaylist:  &youtube.YouTubePlaylist{},
+			Tracks:           []transfer.TrackResult{matched(1, "One", "other")},
+		}}},
+	}
+	for _, run := range runs {
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	mirror, err := store.Mirror("src")
+	if err != nil {
+		t.Fatalf("Mirror() error = %v", err)
+	}
+	if mirror.YouTubePlaylist.ID != "PL1" || len(mirror.Tracks) != 2 {
+		t.Fatalf("Unexpected mirror: %+v", mirror)
+	}
+	videos := map[string]string{}
+	for _, track := range mirror.Tracks {
+		videos[track.Track.Name] = track.Video.ID
+	}
+	if videos["One"] != "v1" || videos["Two"] != "v2" {
+		t.Errorf("Unexpected mapping: %v", videos)
+	}
+
+	if ids, err := store.Mirrored(); err != nil || len(ids) != 1 || ids[0] != "src" {
+		t.Errorf("Mirrored() = %v, %v", ids, err)
+	}
+
+	if _, err := store.Mirror("unknown"); !errors.Is(err, ErrNotTransferred) {
+		t.Errorf("Expected ErrNotTransferred for a playlist that was never transferred, got %v", err)
+	}
+}
+
+func TestDependentRuns(t *testing.T) {
+	store, err := OpenDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenDir() error = %v", err)
+	}
+
+	created := transfer.TransferResult{SourcePlaylistID: "src", YouTubePlaylist: &youtube.YouTubePlaylist{ID: "PL1"}, CreatedPlaylist: true}
+	added := transfer.TransferResult{SourcePlaylistID: "src", YouTubePlaylist: &youtube.YouTubePlaylist{ID: "PL1"}}
+	undone := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{ID: "1", StartedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Playlists: []transfer.TransferResult{created}},
+		{ID: "2", StartedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Command: "sync", Playlists: []transfer.TransferResult{added}},
+		{ID: "3", StartedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), DryRun: true, Playlists: []transfer.TransferResult{added}},
+		{ID: "4", StartedAt: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), UndoneAt: &undone, Playlists: []transfer.TransferResult{added}},
+		{ID: "5", StartedAt: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), Playlists: []transfer.TransferResult{
+			{SourcePlaylistID: "other", YouTubePlaylist: &youtube.YouTubePlaylist{ID: "PL2"}},
+		}},
+	}
+	for _, run := range runs {
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	dependents, err := store.DependentRuns(runs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependents) != 1 || dependents[0].ID != "2" {
+		t.Errorf("DependentRuns() = %+v, want only run 2", dependents)
+	}
+	if dependents, _ := store.DependentRuns(runs[1]); len(dependents) != 0 {
+		t.Errorf("DependentRuns() of a run that created nothing = %+v", dependents)
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -35,14 +36,14 @@ func setupFileLogging() {
 	
 	logDir := filepath.Join(homeDir, ".spotomusic", "logs")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		Logger.Warn("Log directory oluşturulamadı: %v", err)
+		Logger.Warnf("Log directory oluşturulamadı: %v", err)
 		return
 	}
 	
 	logFile := filepath.Join(logDir, "spotomusic.log")
 	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
-		Logger.Warn("Log file açılamadı: %v", err)
+		Logger.Warnf("Log file açılamadı: %v", err)
 		return
 	}
 	
@@ -65,6 +66,24 @@ func SetVerbose(verbose bool) {
 	}
 }
 
+// Configure sets the level by name (trace, debug, info, warn or error);
+// verbose always selects debug
+func Configure(level string, verbose bool) error {
+	if verbose {
+		Logger.SetLevel(logrus.DebugLevel)
+		return nil
+	}
+	if level == "" {
+		level = "info"
+	}
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	Logger.SetLevel(parsed)
+	return nil
+}
+
 // Info logs an info message
 func Info(args ...interface{}) {
 	Logger.Info(args...)
@@ -0,0 +1,111 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"spotomusic/internal/secret"
+)
+
+// Default is the profile used when none is selected. It keeps the file
+// locations of earlier versions, directly in the home directory.
+const Default = "default"
+
+// validName keeps profile names usable as directory names
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Profile is a named account setup: its own OAuth clients, tokens and
+// playlists
+type Profile struct {
+	Name string
+	// Dir holds the secrets of the profile
+	Dir string
+	// Secrets keeps the tokens and the stored OAuth client credentials
+	Secrets secret.Store
+	// YouTubeCredentialsFile and SpotifyCredentialsFile are OAuth client
+	// files used instead of the stored credentials
+	YouTubeCredentialsFile string
+	SpotifyCredentialsFile string
+	// PlaylistLinks are the Spotify playlists used when
+	// SPOTIFY_PLAYLIST_LINKS is not set
+	PlaylistLinks []string
+}
+
+// New returns the profile with its default file locations. Named profiles
+// live in ~/.spotomusic/profiles/<name>, which is created if needed.
+func New(name string) (Profile, error) {
+	if name == "" {
+		name = Default
+	}
+	if !validName.MatchString(name) {
+		return Profile{}, fmt.Errorf("invalid profile name %q (use letters, digits, '.', '-' and '_')", name)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Profile{}, fmt.Errorf("home directory bulunamadı: %v", err)
+	}
+
+	dir := homeDir
+	if name != Default {
+		dir = filepath.Join(homeDir, ".spotomusic", "profiles", name)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return Profile{}, fmt.Errorf("profile directory oluşturulamadı: %v", err)
+		}
+	}
+
+	return Profile{
+		Name:    name,
+		Dir:     dir,
+		Secrets: secret.FileStore{Dir: dir},
+	}, nil
+}
+
+// DataDir returns where the profile keeps its transfer history and retry
+// lists: ~/.spotomusic for the default profile, the profile directory for
+// named ones. Runs of one profile are never undone or synced with the
+// tokens of another.
+func (p Profile) DataDir() (string, error) {
+	if p.Dir == "" {
+		return "", fmt.Errorf("profile %s has no directory", p.Name)
+	}
+	dir := p.Dir
+	if p.Name == Default {
+		dir = filepath.Join(p.Dir, ".spotomusic")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("data directory oluşturulamadı: %v", err)
+	}
+	return dir, nil
+}
+
+var (
+	mu     sync.Mutex
+	active *Profile
+)
+
+// Activate selects the profile used by the YouTube and Spotify clients
+func Activate(p Profile) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = &p
+}
+
+// Active returns the selected profile, the default one if none was
+// activated
+func Active() Profile {
+	mu.Lock()
+	defer mu.Unlock()
+	if active == nil {
+		p, err := New(Default)
+		if err != nil {
+			// Without a home directory the clients fail on their own
+			return Profile{Name: Default, Secrets: secret.FileStore{}}
+		}
+		active = &p
+	}
+	return *active
+}
@@ -0,0 +1,42 @@
+package profile
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	p, err := New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != Default || p.Dir != home {
+		t.Errorf("default profile = %+v, want the home directory", p)
+	}
+
+	p, err = New("brand-account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDir := filepath.Join(home, ".spotomusic", "profiles", "brand-account")
+	if p.Dir != wantDir || p.Secrets.Location("token.json") != filepath.Join(wantDir, "token.json") {
+		t.Errorf("named profile = %+v", p)
+	}
+
+	if dir, err := p.DataDir(); err != nil || dir != wantDir {
+		t.Errorf("DataDir() of a named profile = %q, %v", dir, err)
+	}
+	p, _ = New("")
+	if dir, err := p.DataDir(); err != nil || dir != filepath.Join(home, ".spotomusic") {
+		t.Errorf("DataDir() of the default profile = %q, %v", dir, err)
+	}
+
+	for _, name := range []string{"../escape", "a/b", ".hidden"} {
+		if _, err := New(name); err == nil {
+			t.Errorf("New(%q) should fail", name)
+		}
+	}
+}
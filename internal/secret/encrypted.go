@@ -0,0 +1,171 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedMagic starts every encrypted file and names its format version
+var encryptedMagic = []byte("spotomusic-secret-v1\n")
+
+// scrypt parameters for deriving the per-file key
+const (
+	saltSize = 16
+	scryptN  = 1 << 15
+	scryptR  = 8
+	scryptP  = 1
+	keySize  = 32
+)
+
+// minKeySize is the shortest accepted SPOTOMUSIC_SECRET_KEY
+const minKeySize = 16
+
+// EncryptedStore keeps every secret in its own AES-256-GCM encrypted file,
+// the secret name with an .enc suffix. Each file has a random salt from
+// which its key is derived with scrypt, and the secret name is
+// authenticated so files cannot be swapped.
+//
+// Plain files left by FileStore are encrypted on first read and removed.
+type EncryptedStore struct {
+	plain  FileStore
+	master []byte
+}
+
+// NewEncryptedStore returns a store in dir that encrypts with master, a
+// passphrase or a random key
+func NewEncryptedStore(dir string, master []byte) *EncryptedStore {
+	return &EncryptedStore{plain: FileStore{Dir: dir}, master: master}
+}
+
+// masterSecretFromEnv reads SPOTOMUSIC_SECRET_KEY, a base64 encoded random
+// key, or SPOTOMUSIC_SECRET_PASSPHRASE
+func masterSecretFromEnv() ([]byte, error) {
+	if encoded := os.Getenv(KeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not valid base64: %v", KeyEnv, err)
+		}
+		if len(key) < minKeySize {
+			return nil, fmt.Errorf("%s must decode to at least %d bytes", KeyEnv, minKeySize)
+		}
+		return key, nil
+	}
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	return nil, fmt.Errorf("the encrypted secret store needs %s or %s", KeyEnv, PassphraseEnv)
+}
+
+func (s *EncryptedStore) Get(name string) ([]byte, error) {
+	data, err := os.ReadFile(s.Location(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return s.migrate(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.open(name, data)
+}
+
+func (s *EncryptedStore) Set(name string, data []byte) error {
+	sealed, err := s.seal(name, data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.Location(name), sealed, 0600)
+}
+
+func (s *EncryptedStore) Delete(name string) error {
+	if err := os.Remove(s.Location(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.plain.Delete(name)
+}
+
+func (s *EncryptedStore) Location(name string) string {
+	return s.plain.Location(name) + ".enc"
+}
+
+// migrate encrypts a plain file saved before the store was switched
+func (s *EncryptedStore) migrate(name string) ([]byte, error) {
+	data, err := s.plain.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Set(name, data); err != nil {
+		return nil, fmt.Errorf("%s şifrelenemedi: %v", s.plain.Location(name), err)
+	}
+	if err := s.plain.Delete(name); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// seal encrypts data as magic | salt | nonce | ciphertext
+func (s *EncryptedStore) seal(name string, data []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append([]byte{}, encryptedMagic...)
+	sealed = append(sealed, salt...)
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, data, []byte(name)), nil
+}
+
+// open reverses seal
+func (s *EncryptedStore) open(name string, sealed []byte) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, encryptedMagic) {
+		return nil, fmt.Errorf("%s is not an encrypted secret", s.Location(name))
+	}
+	sealed = sealed[len(encryptedMagic):]
+	if len(sealed) < saltSize {
+		return nil, fmt.Errorf("%s is truncated", s.Location(name))
+	}
+	salt, sealed := sealed[:saltSize], sealed[saltSize:]
+
+	aead, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%s is truncated", s.Location(name))
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	data, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s could not be decrypted; check %s or %s", s.Location(name), KeyEnv, PassphraseEnv)
+	}
+	return data, nil
+}
+
+// cipher derives the file key from the master secret and salt
+func (s *EncryptedStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(s.master, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
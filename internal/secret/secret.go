@@ -0,0 +1,107 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by Get for secrets that were never stored
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps secrets such as OAuth tokens and client credentials under a
+// name
+type Store interface {
+	Get(name string) ([]byte, error)
+	Set(name string, data []byte) error
+	// Delete removes a secret; deleting a missing secret is not an error
+	Delete(name string) error
+	// Location describes where a secret is kept, for messages
+	Location(name string) string
+}
+
+// Backends accepted by Open
+const (
+	BackendFile      = "file"
+	BackendEncrypted = "encrypted"
+)
+
+// Environment variables holding the encryption secret, see NewEncryptedStore
+const (
+	KeyEnv        = "SPOTOMUSIC_SECRET_KEY"
+	PassphraseEnv = "SPOTOMUSIC_SECRET_PASSPHRASE"
+)
+
+// Open returns the store for backend in dir. An empty backend picks the
+// encrypted store when a key or passphrase is set in the environment and
+// plain files otherwise.
+func Open(backend, dir string) (Store, error) {
+	if backend == "" {
+		backend = BackendFile
+		if os.Getenv(KeyEnv) != "" || os.Getenv(PassphraseEnv) != "" {
+			backend = BackendEncrypted
+		}
+	}
+
+	switch strings.ToLower(backend) {
+	case BackendFile:
+		return FileStore{Dir: dir}, nil
+	case BackendEncrypted:
+		master, err := masterSecretFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return NewEncryptedStore(dir, master), nil
+	}
+	return nil, fmt.Errorf("unknown secret backend %q (expected file or encrypted)", backend)
+}
+
+// Unavailable returns a store for dir that fails every read and write with
+// err. It stands in for a store that could not be opened, so commands that
+// need no secrets still run.
+func Unavailable(dir string, err error) Store {
+	return unavailableStore{plain: FileStore{Dir: dir}, err: err}
+}
+
+type unavailableStore struct {
+	plain FileStore
+	err   error
+}
+
+func (s unavailableStore) Get(name string) ([]byte, error) { return nil, s.err }
+
+func (s unavailableStore) Set(name string, data []byte) error { return s.err }
+
+func (s unavailableStore) Delete(name string) error { return s.err }
+
+func (s unavailableStore) Location(name string) string { return s.plain.Location(name) }
+
+// FileStore keeps every secret in a plain file readable only by the user
+type FileStore struct {
+	Dir string
+}
+
+func (s FileStore) Get(name string) ([]byte, error) {
+	data, err := os.ReadFile(s.Location(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s FileStore) Set(name string, data []byte) error {
+	return os.WriteFile(s.Location(name), data, 0600)
+}
+
+func (s FileStore) Delete(name string) error {
+	if err := os.Remove(s.Location(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s FileStore) Location(name string) string {
+	return filepath.Join(s.Dir, name)
+}
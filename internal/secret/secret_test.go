@@ -0,0 +1,104 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewEncryptedStore(dir, []byte("correct horse battery staple"))
+
+	if _, err := store.Get("token.json"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() error = %v, want ErrNotFound", err)
+	}
+
+	secret := []byte(`{"refresh_token":"very-secret"}`)
+	if err := store.Set("token.json", secret); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "token.json.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("very-secret")) {
+		t.Error("the encrypted file contains the secret in the clear")
+	}
+
+	got, err := store.Get("token.json")
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("Get() = %s, %v", got, err)
+	}
+
+	if _, err := NewEncryptedStore(dir, []byte("wrong")).Get("token.json"); err == nil {
+		t.Error("Get() with the wrong passphrase should fail")
+	}
+
+	// A file renamed to another secret must not decrypt
+	os.Rename(filepath.Join(dir, "token.json.enc"), filepath.Join(dir, "other.json.enc"))
+	if _, err := store.Get("other.json"); err == nil {
+		t.Error("Get() of a swapped file should fail")
+	}
+}
+
+func TestEncryptedStoreMigratesPlainFiles(t *testing.T) {
+	dir := t.TempDir()
+	plain := FileStore{Dir: dir}
+	if err := plain.Set("token.json", []byte("plain")); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewEncryptedStore(dir, []byte("passphrase"))
+	got, err := store.Get("token.json")
+	if err != nil || string(got) != "plain" {
+		t.Fatalf("Get() = %s, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "token.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("the plain file was not removed")
+	}
+	if got, err := store.Get("token.json"); err != nil || string(got) != "plain" {
+		t.Errorf("Get() after migration = %s, %v", got, err)
+	}
+
+	if err := store.Delete("token.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("token.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	t.Setenv(PassphraseEnv, "")
+
+	if store, err := Open("", "dir"); err != nil {
+		t.Fatal(err)
+	} else if _, ok := store.(FileStore); !ok {
+		t.Errorf("Open() without a key = %T, want FileStore", store)
+	}
+	if _, err := Open(BackendEncrypted, "dir"); err == nil {
+		t.Error("Open(encrypted) without a key should fail")
+	}
+
+	t.Setenv(KeyEnv, "short")
+	if _, err := Open("", "dir"); err == nil {
+		t.Error("Open() with an invalid key should fail")
+	}
+
+	t.Setenv(KeyEnv, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if store, err := Open("", "dir"); err != nil {
+		t.Fatal(err)
+	} else if _, ok := store.(*EncryptedStore); !ok {
+		t.Errorf("Open() with a key = %T, want *EncryptedStore", store)
+	}
+
+	if _, err := Open("keychain", "dir"); err == nil {
+		t.Error("Open() of an unknown backend should fail")
+	}
+}
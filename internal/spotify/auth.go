@@ -0,0 +1,207 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"spotomusic/internal/auth"
+	"spotomusic/internal/profile"
+	"spotomusic/internal/secret"
+
+	"golang.org/x/oauth2"
+)
+
+// Endpoint is Spotify's OAuth2 endpoint
+var Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://accounts.spotify.com/authorize",
+	TokenURL: "https://accounts.spotify.com/api/token",
+}
+
+// Scopes needed to read the user's playlists and create new ones
+var Scopes = []string{
+	"playlist-read-private",
+	"playlist-modify-private",
+	"playlist-modify-public",
+}
+
+// Secret names in the store of a profile, the file names of earlier
+// versions
+const (
+	spotifyTokenFile  = ".spotomusic_spotify_token.json"
+	credentialsSecret = ".spotomusic_spotify_credentials.json"
+)
+
+const (
+	// Spotify only accepts loopback IP literals as redirect hosts
+	spotifyRedirectAddr = "127.0.0.1:8082"
+)
+
+// appCredentials are the client ID and secret of a Spotify app
+type appCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// loadOAuthConfig reads the app credentials from SPOTIFY_CLIENT_ID and
+// SPOTIFY_CLIENT_SECRET, the configured credentials file or the secret
+// store of the active profile
+func loadOAuthConfig() (*oauth2.Config, error) {
+	credentials := appCredentials{
+		ClientID:     os.Getenv("SPOTIFY_CLIENT_ID"),
+		ClientSecret: os.Getenv("SPOTIFY_CLIENT_SECRET"),
+	}
+
+	if credentials.ClientID == "" {
+		active := profile.Active()
+		var data []byte
+		var err error
+		if active.SpotifyCredentialsFile != "" {
+			data, err = os.ReadFile(active.SpotifyCredentialsFile)
+		} else {
+			data, err = active.Secrets.Get(credentialsSecret)
+		}
+		if errors.Is(err, secret.ErrNotFound) {
+			return nil, fmt.Errorf("Spotify credentials bulunamadı. SPOTIFY_CLIENT_ID/SPOTIFY_CLIENT_SECRET ayarlayın veya 'spotomusic auth credentials spotify <file>' ile kaydedin")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Spotify credentials okunamadı: %v", err)
+		}
+		if err := json.Unmarshal(data, &credentials); err != nil {
+			return nil, fmt.Errorf("Spotify credentials parse edilemedi: %v", err)
+		}
+	}
+
+	return &oauth2.Config{
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+		Endpoint:     Endpoint,
+		Scopes:       Scopes,
+		RedirectURL:  "http://" + spotifyRedirectAddr + "/callback",
+	}, nil
+}
+
+// StoreCredentials saves a JSON file with the client_id and client_secret of
+// a Spotify app in the secret store of the active profile
+func StoreCredentials(credentialsJSON []byte) error {
+	var credentials appCredentials
+	if err := json.Unmarshal(credentialsJSON, &credentials); err != nil || credentials.ClientID == "" {
+		return fmt.Errorf("Spotify credentials parse edilemedi: client_id ve client_secret içeren bir JSON dosyası gerekli")
+	}
+	if err := profile.Active().Secrets.Set(credentialsSecret, credentialsJSON); err != nil {
+		return fmt.Errorf("Spotify credentials kaydedilemedi: %v", err)
+	}
+	return nil
+}
+
+// Login runs flow and saves the resulting token, replacing any saved one.
+// Spotify has no device authorization endpoint, so auth.Device fails with
+// auth.ErrDeviceFlowUnsupported.
+func Login(ctx context.Context, flow auth.Flow) error {
+	config, err := loadOAuthConfig()
+	if err != nil {
+		return err
+	}
+	file, err := tokenFile()
+	if err != nil {
+		return err
+	}
+	token, err := flow.Login(ctx, config)
+	if err != nil {
+		return fmt.Errorf("Spotify authentication failed: %w", err)
+	}
+	if err := file.Save(token); err != nil {
+		return fmt.Errorf("Spotify token kaydedilemedi: %v", err)
+	}
+	return nil
+}
+
+// Logout removes the saved token
+func Logout() error {
+	file, err := tokenFile()
+	if err != nil {
+		return err
+	}
+	if err := file.Remove(); err != nil {
+		return fmt.Errorf("Spotify token silinemedi: %v", err)
+	}
+	return nil
+}
+
+// Status describes the saved login without starting a new one. It returns
+// auth.ErrNotLoggedIn when no token is saved. The status is returned even
+// when the account lookup fails.
+func Status(ctx context.Context) (*auth.Status, error) {
+	file, err := tokenFile()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := file.Load()
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadOAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := saved.Scopes
+	if len(scopes) == 0 {
+		scopes = Scopes
+	}
+	status := &auth.Status{
+		Service:     "spotify",
+		Location:    file.Location(),
+		Scopes:      scopes,
+		Expiry:      saved.Expiry,
+		Refreshable: saved.RefreshToken != "",
+	}
+
+	client := newUserClient(ctx, config, file, &saved.Token)
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return status, err
+	}
+	status.Account = user.DisplayName
+	if status.Account == "" {
+		status.Account = user.ID
+	}
+	return status, nil
+}
+
+// Refresh exchanges the refresh token for a new access token and saves it
+func Refresh(ctx context.Context) (*auth.Status, error) {
+	config, err := loadOAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+	file, err := tokenFile()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := file.Load()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Refresh(ctx, config, &saved.Token); err != nil {
+		return nil, fmt.Errorf("Spotify token yenilenemedi: %v", err)
+	}
+	return Status(ctx)
+}
+
+// authenticateSpotify performs the OAuth2 login. Spotify only redirects to
+// registered URIs, so the callback server needs the fixed address.
+func authenticateSpotify(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	return auth.Loopback{Addr: spotifyRedirectAddr, Path: "/callback"}.Login(ctx, config)
+}
+
+// tokenFile returns the token of the active profile
+func tokenFile() (auth.SavedToken, error) {
+	active := profile.Active()
+	if active.Dir == "" {
+		return auth.SavedToken{}, fmt.Errorf("home directory bulunamadı")
+	}
+	return auth.SavedToken{Store: active.Secrets, Name: spotifyTokenFile}, nil
+}
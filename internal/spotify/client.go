@@ -1,6 +1,7 @@
 package spotify
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html"
@@ -11,10 +12,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"spotomusic/internal/profile"
 )
 
 type Client struct {
 	httpClient *http.Client
+	// authenticated is set for clients created with NewUserClient
+	authenticated bool
 }
 
 type Playlist struct {
@@ -23,7 +28,13 @@ type Playlist struct {
 	Description string `json:"description"`
 	TrackCount  int    `json:"track_count"`
 	Public      bool   `json:"public"`
-	Owner       string `json:"owner"`
+	// Owner is the display name of the owner, empty when unknown
+	Owner string `json:"owner"`
+	// OwnerID is the account ID of the owner, set for playlists read
+	// through the user API
+	OwnerID string `json:"owner_id,omitempty"`
+	// Image is the cover image URL, empty when unknown
+	Image string `json:"image,omitempty"`
 }
 
 type Track struct {
@@ -46,15 +57,18 @@ func NewClient() (*Client, error) {
 }
 
 // GetUserPlaylists retrieves playlists from provided links
-func (c *Client) GetUserPlaylists() ([]Playlist, error) {
-	// Get playlist links from environment variable
-	playlistLinks := os.Getenv("SPOTIFY_PLAYLIST_LINKS")
-	if playlistLinks == "" {
-		return nil, fmt.Errorf("SPOTIFY_PLAYLIST_LINKS environment variable required (comma-separated playlist URLs)")
+func (c *Client) GetUserPlaylists(ctx context.Context) ([]Playlist, error) {
+	// Get playlist links from environment variable, then from the profile
+	var links []string
+	if playlistLinks := os.Getenv("SPOTIFY_PLAYLIST_LINKS"); playlistLinks != "" {
+		links = strings.Split(playlistLinks, ",")
+	} else {
+		links = profile.Active().PlaylistLinks
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("SPOTIFY_PLAYLIST_LINKS environment variable (comma-separated playlist URLs) or spotify.playlist_links required")
 	}
 
-	// Split links by comma
-	links := strings.Split(playlistLinks, ",")
 	var result []Playlist
 
 	for _, link := range links {
@@ -71,7 +85,7 @@ func (c *Client) GetUserPlaylists() ([]Playlist, error) {
 		}
 
 		// Get playlist info
-		playlist, err := c.GetPlaylistInfo(playlistID, playlistID) // Pass playlistID as name for now
+		playlist, err := c.GetPlaylistInfo(ctx, playlistID, playlistID) // Pass playlistID as name for now
 		if err != nil {
 			fmt.Printf("Warning: Failed to get playlist %s: %v\n", link, err)
 			continue
@@ -83,6 +97,14 @@ func (c *Client) GetUserPlaylists() ([]Playlist, error) {
 	return result, nil
 }
 
+// ParsePlaylistID accepts a playlist ID, URL or URI and returns the ID
+func ParsePlaylistID(ref string) string {
+	if id, err := (&Client{}).extractPlaylistID(ref); err == nil {
+		return id
+	}
+	return ref
+}
+
 // extractPlaylistID extracts playlist ID from Spotify URL
 func (c *Client) extractPlaylistID(url string) (string, error) {
 	// Handle different Spotify URL formats:
@@ -111,10 +133,10 @@ func (c *Client) extractPlaylistID(url string) (string, error) {
 }
 
 // GetPlaylistInfo gets playlist information using Spotify embed API
-func (c *Client) GetPlaylistInfo(playlistID string, playlistName string) (Playlist, error) {
+func (c *Client) GetPlaylistInfo(ctx context.Context, playlistID string, playlistName string) (Playlist, error) {
 	// Use Spotify embed API which provides better data
 	url := fmt.Sprintf("https://open.spotify.com/embed/playlist/%s", playlistID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return Playlist{}, fmt.Errorf("request oluşturulamadı: %v", err)
 	}
@@ -149,7 +171,7 @@ func (c *Client) GetPlaylistInfo(playlistID string, playlistName string) (Playli
 	}
 
 	// Get tracks to determine the actual track count
-	tracks, err := c.GetPlaylistTracks(playlistID)
+	tracks, err := c.GetPlaylistTracks(ctx, playlistID)
 	if err == nil {
 		playlist.TrackCount = len(tracks)
 	}
@@ -157,41 +179,101 @@ func (c *Client) GetPlaylistInfo(playlistID string, playlistName string) (Playli
 	return playlist, nil
 }
 
-// parsePlaylistFromEmbedHTML extracts playlist information from embed HTML content
+// parsePlaylistFromEmbedHTML extracts playlist information from embed HTML
+// content. A playlistName other than "Unknown Playlist" is kept as the name.
+// Owner and Description stay empty when the page does not show them.
 func (c *Client) parsePlaylistFromEmbedHTML(htmlContent, playlistID, playlistName string) (Playlist, error) {
-	// In this simplified approach, we are not extracting playlist name or track count from HTML directly.
-	// These will be provided via CLI arguments or derived from track scraping results.
-	// However, we still need to return a basic Playlist object.
-
-	// Try to extract playlist name from the og:title meta tag as a fallback for the playlist name
-	// Use the provided playlistName if available, otherwise try to extract from HTML
-	if playlistName != "Unknown Playlist" {
-		return Playlist{
-			ID:          playlistID,
-			Name:        playlistName,
-			Description: "",
-			TrackCount:  0,
-			Public:      true,
-			Owner:       "Unknown",
-		}, nil
-	}
-
-	playlistName = "Unknown Playlist"
-	ogTitleRegex := regexp.MustCompile(`<meta property="og:title" content="([^"]+)"\/>`)
-	ogTitleMatches := ogTitleRegex.FindStringSubmatch(htmlContent)
-	
-	if len(ogTitleMatches) > 1 {
-		playlistName = strings.TrimSuffix(strings.TrimSpace(ogTitleMatches[1]), " Spotify")
+	playlist := Playlist{
+		ID:     playlistID,
+		Name:   playlistName,
+		Public: true,
+	}
+	entity := parseEmbedEntity(htmlContent)
+	meta := openGraphTags(htmlContent)
+
+	if playlist.Name == "Unknown Playlist" {
+		switch {
+		case entity.Name != "":
+			playlist.Name = entity.Name
+		case meta["title"] != "":
+			playlist.Name = strings.TrimSuffix(strings.TrimSpace(meta["title"]), " Spotify")
+		}
 	}
 
-	return Playlist{
-		ID:          playlistID,
-		Name:        playlistName,
-		Description: "",
-		TrackCount:  0,
-		Public:      true,
-		Owner:       "Unknown",
-	}, nil
+	// og:description is either the description or a generated summary
+	// such as "Playlist · owner · 42 items"
+	summary, isSummary := strings.CutPrefix(meta["description"], "Playlist · ")
+	playlist.Owner = entity.Subtitle
+	if playlist.Owner == "" && len(entity.Authors) > 0 {
+		playlist.Owner = entity.Authors[0].Name
+	}
+	if playlist.Owner == "" && isSummary {
+		playlist.Owner = strings.TrimSpace(strings.Split(summary, " · ")[0])
+	}
+	playlist.Description = entity.Description
+	if playlist.Description == "" && !isSummary {
+		playlist.Description = meta["description"]
+	}
+
+	playlist.Image = meta["image"]
+	if len(entity.CoverArt.Sources) > 0 {
+		playlist.Image = entity.CoverArt.Sources[0].URL
+	}
+
+	return playlist, nil
+}
+
+// embedEntity is the playlist part of the data of an embed page
+type embedEntity struct {
+	Name        string `json:"name"`
+	Subtitle    string `json:"subtitle"`
+	Description string `json:"description"`
+	Authors     []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	CoverArt struct {
+		Sources []struct {
+			URL string `json:"url"`
+		} `json:"sources"`
+	} `json:"coverArt"`
+}
+
+// parseEmbedEntity reads the playlist entity from the Next.js data of an
+// embed page, or returns an empty entity
+func parseEmbedEntity(htmlContent string) embedEntity {
+	dataRegex := regexp.MustCompile(`(?s)<script id="__NEXT_DATA__" type="application/json">(.+?)</script>`)
+	matches := dataRegex.FindStringSubmatch(htmlContent)
+	if len(matches) < 2 {
+		return embedEntity{}
+	}
+
+	var data struct {
+		Props struct {
+			PageProps struct {
+				State struct {
+					Data struct {
+						Entity embedEntity `json:"entity"`
+					} `json:"data"`
+				} `json:"state"`
+			} `json:"pageProps"`
+		} `json:"props"`
+	}
+	if err := json.Unmarshal([]byte(matches[1]), &data); err != nil {
+		return embedEntity{}
+	}
+	return data.Props.PageProps.State.Data.Entity
+}
+
+// openGraphTags returns the og: meta tags of a page by name, e.g. "title"
+func openGraphTags(htmlContent string) map[string]string {
+	tags := make(map[string]string)
+	metaRegex := regexp.MustCompile(`<meta property="og:(\w+)" content="([^"]*)"\s*/?>`)
+	for _, match := range metaRegex.FindAllStringSubmatch(htmlContent, -1) {
+		if _, seen := tags[match[1]]; !seen {
+			tags[match[1]] = html.UnescapeString(match[2])
+		}
+	}
+	return tags
 }
 
 // parsePlaylistFromHTML extracts playlist information from HTML content
@@ -282,15 +364,14 @@ func (c *Client) parsePlaylistFromHTMLFallback(html, playlistID string) (Playlis
 		Description: "",
 		TrackCount:  trackCount,
 		Public:      true, // Assume public if we can access it
-		Owner:       "Unknown",
 	}, nil
 }
 
 // GetPlaylistTracks retrieves all tracks from a specific playlist using embed API
-func (c *Client) GetPlaylistTracks(playlistID string) ([]Track, error) {
+func (c *Client) GetPlaylistTracks(ctx context.Context, playlistID string) ([]Track, error) {
 	// Use Spotify embed API which provides better track data
 	url := fmt.Sprintf("https://open.spotify.com/embed/playlist/%s", playlistID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request oluşturulamadı: %v", err)
 	}
@@ -566,11 +647,16 @@ func (c *Client) extractTracksFromTextPatterns(html string) []Track {
 	return tracks
 }
 
-// SearchTrack searches for a track on Spotify using direct HTTP requests
-func (c *Client) SearchTrack(query string) ([]Track, error) {
+// SearchTrack searches for a track on Spotify using direct HTTP requests.
+// The search endpoint needs a client created with NewUserClient.
+func (c *Client) SearchTrack(ctx context.Context, query string) ([]Track, error) {
+	if !c.authenticated {
+		return nil, fmt.Errorf("Spotify search requires a login (set SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET)")
+	}
+
 	// Search tracks using direct HTTP request
 	url := fmt.Sprintf("https://api.spotify.com/v1/search?q=%s&type=track&limit=5", url.QueryEscape(query))
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request oluşturulamadı: %v", err)
 	}
@@ -638,9 +724,9 @@ func (c *Client) SearchTrack(query string) ([]Track, error) {
 }
 
 // SaveHTMLForAnalysis saves the HTML content to a file for debugging
-func (c *Client) SaveHTMLForAnalysis(playlistID string) error {
+func (c *Client) SaveHTMLForAnalysis(ctx context.Context, playlistID string) error {
 	url := fmt.Sprintf("https://open.spotify.com/playlist/%s", playlistID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("request oluşturulamadı: %v", err)
 	}
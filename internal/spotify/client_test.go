@@ -22,13 +22,13 @@ func TestBuildSearchQuery(t *testing.T) {
 			name:     "Track with ft.",
 			artist:   "Ed Sheeran ft. Justin Bieber",
 			title:    "I Don't Care",
-			expected: "Ed Sheeran  Justin Bieber I Don't Care",
+			expected: "Ed Sheeran Justin Bieber I Don't Care",
 		},
 		{
 			name:     "Track with feat.",
 			artist:   "Ariana Grande feat. Nicki Minaj",
 			title:    "Side to Side",
-			expected: "Ariana Grande  Nicki Minaj Side to Side",
+			expected: "Ariana Grande Nicki Minaj Side to Side",
 		},
 	}
 
@@ -63,3 +63,67 @@ func buildSearchQuery(track Track) string {
 	
 	return query
 }
+
+func TestParsePlaylistFromEmbedHTML(t *testing.T) {
+	html := `<head><meta property="og:title" content="Road Trip"/>` +
+		`<meta property="og:image" content="https://i.scdn.co/image/ab67706c0000da84"/></head>`
+
+	playlist, err := (&Client{}).parsePlaylistFromEmbedHTML(html, "abc", "Unknown Playlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playlist.Name != "Road Trip" {
+		t.Errorf("Name = %q", playlist.Name)
+	}
+	if playlist.Image != "https://i.scdn.co/image/ab67706c0000da84" {
+		t.Errorf("Image = %q", playlist.Image)
+	}
+}
+
+func TestParsePlaylistFromEmbedHTMLReadsOwnerAndDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		owner       string
+		description string
+	}{
+		{
+			name: "Embed data",
+			html: `<meta property="og:description" content="Playlist · deniz · 42 items"/>` +
+				`<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"state":{"data":{"entity":` +
+				`{"name":"Road Trip","subtitle":"deniz","description":"Songs for the road","coverArt":{"sources":[{"url":"https://i.scdn.co/image/cover"}]}}}}}}}</script>`,
+			owner:       "deniz",
+			description: "Songs for the road",
+		},
+		{
+			name:        "Description meta tag",
+			html:        `<meta property="og:description" content="Rock &amp; roll classics"/>`,
+			owner:       "",
+			description: "Rock & roll classics",
+		},
+		{
+			name:        "Generated summary",
+			html:        `<meta property="og:description" content="Playlist · deniz · 42 items"/>`,
+			owner:       "deniz",
+			description: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist, err := (&Client{}).parsePlaylistFromEmbedHTML(tt.html, "abc", "Road Trip")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if playlist.Name != "Road Trip" {
+				t.Errorf("Name = %q", playlist.Name)
+			}
+			if playlist.Owner != tt.owner {
+				t.Errorf("Owner = %q, want %q", playlist.Owner, tt.owner)
+			}
+			if playlist.Description != tt.description {
+				t.Errorf("Description = %q, want %q", playlist.Description, tt.description)
+			}
+		})
+	}
+}
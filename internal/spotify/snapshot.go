@@ -0,0 +1,137 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// snapshotVersion is bumped whenever the snapshot file format changes
+const snapshotVersion = 1
+
+// Snapshot is an offline copy of playlists, so transfers can be reproduced
+// after the Spotify pages change
+type Snapshot struct {
+	Version   int                `json:"version"`
+	CreatedAt time.Time          `json:"created_at"`
+	Playlists []PlaylistSnapshot `json:"playlists"`
+}
+
+// PlaylistSnapshot is one playlist with all of its tracks
+type PlaylistSnapshot struct {
+	Playlist  Playlist  `json:"playlist"`
+	Tracks    []Track   `json:"tracks"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// TakeSnapshot fetches every configured playlist with its tracks. Playlists
+// that cannot be fetched are skipped with a warning.
+func (c *Client) TakeSnapshot(ctx context.Context) (*Snapshot, error) {
+	playlists, err := c.GetUserPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Version:   snapshotVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, playlist := range playlists {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		tracks, err := c.GetPlaylistTracks(ctx, playlist.ID)
+		if err != nil {
+			fmt.Printf("Warning: Failed to get tracks for %s: %v\n", playlist.Name, err)
+			continue
+		}
+		playlist.TrackCount = len(tracks)
+
+		snapshot.Playlists = append(snapshot.Playlists, PlaylistSnapshot{
+			Playlist:  playlist,
+			Tracks:    tracks,
+			FetchedAt: time.Now().UTC(),
+		})
+	}
+
+	return snapshot, nil
+}
+
+// WriteFile saves the snapshot as JSON
+func (s *Snapshot) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("snapshot dosyası yazılamadı: %v", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot saved with WriteFile
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot dosyası okunamadı: %v", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("snapshot parse edilemedi: %v", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (expected %d)", snapshot.Version, snapshotVersion)
+	}
+	return &snapshot, nil
+}
+
+// The methods below let a snapshot stand in for a Client as a playlist
+// source.
+
+// GetUserPlaylists returns the playlists stored in the snapshot
+func (s *Snapshot) GetUserPlaylists(ctx context.Context) ([]Playlist, error) {
+	playlists := make([]Playlist, 0, len(s.Playlists))
+	for _, playlist := range s.Playlists {
+		playlists = append(playlists, playlist.Playlist)
+	}
+	return playlists, nil
+}
+
+// GetPlaylistInfo returns the stored metadata of a playlist
+func (s *Snapshot) GetPlaylistInfo(ctx context.Context, playlistID string, playlistName string) (Playlist, error) {
+	playlist, err := s.find(playlistID)
+	if err != nil {
+		return Playlist{}, err
+	}
+	return playlist.Playlist, nil
+}
+
+// GetPlaylistTracks returns the stored tracks of a playlist
+func (s *Snapshot) GetPlaylistTracks(ctx context.Context, playlistID string) ([]Track, error) {
+	playlist, err := s.find(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return playlist.Tracks, nil
+}
+
+// find looks a playlist up by ID, URL or case-insensitive name
+func (s *Snapshot) find(ref string) (*PlaylistSnapshot, error) {
+	id := ParsePlaylistID(ref)
+	for i := range s.Playlists {
+		if s.Playlists[i].Playlist.ID == id {
+			return &s.Playlists[i], nil
+		}
+	}
+	for i := range s.Playlists {
+		if strings.EqualFold(s.Playlists[i].Playlist.Name, ref) {
+			return &s.Playlists[i], nil
+		}
+	}
+	return nil, fmt.Errorf("playlist %s is not in the snapshot", ref)
+}
@@ -0,0 +1,44 @@
+package spotify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := &Snapshot{
+		Version:   snapshotVersion,
+		CreatedAt: time.Now().UTC(),
+		Playlists: []PlaylistSnapshot{{
+			Playlist:  Playlist{ID: "37i9dQZF1DXcBWIGoYBM5M", Name: "Today's Top Hits", TrackCount: 1},
+			Tracks:    []Track{{ID: "7qiZfU4dY1lWllzX7mPBI3", Name: "Shape of You", Artist: "Ed Sheeran", Duration: 233712}},
+			FetchedAt: time.Now().UTC(),
+		}},
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := snapshot.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for _, ref := range []string{"37i9dQZF1DXcBWIGoYBM5M", "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc", "today's top hits"} {
+		tracks, err := loaded.GetPlaylistTracks(ctx, ref)
+		if err != nil {
+			t.Fatalf("GetPlaylistTracks(%q) error = %v", ref, err)
+		}
+		if len(tracks) != 1 || tracks[0].Duration != 233712 {
+			t.Errorf("GetPlaylistTracks(%q) = %+v", ref, tracks)
+		}
+	}
+
+	if _, err := loaded.GetPlaylistInfo(ctx, "missing", ""); err == nil {
+		t.Error("Expected an error for a playlist that is not in the snapshot")
+	}
+}
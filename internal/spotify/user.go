@@ -0,0 +1,239 @@
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"spotomusic/internal/auth"
+
+	"golang.org/x/oauth2"
+)
+
+const apiBaseURL = "https://api.spotify.com/v1"
+
+// maxTracksPerRequest is the Web API limit for adding tracks in one call
+const maxTracksPerRequest = 100
+
+// NewUserClient creates a Spotify client that acts on behalf of the logged-in
+// user. It is needed for searching and for writing playlists.
+func NewUserClient(ctx context.Context) (*Client, error) {
+	config, err := loadOAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := tokenFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var token *oauth2.Token
+	if saved, err := file.Load(); err == nil {
+		token = &saved.Token
+	} else {
+		// No saved token, need to authenticate
+		token, err = authenticateSpotify(ctx, config)
+		if err != nil {
+			return nil, fmt.Errorf("Spotify authentication failed: %v", err)
+		}
+		if err := file.Save(token); err != nil {
+			fmt.Printf("Warning: Spotify token kaydedilemedi: %v\n", err)
+		}
+	}
+
+	return newUserClient(ctx, config, file, token), nil
+}
+
+// newUserClient creates a client acting with token. Refreshed tokens are
+// saved to file so they outlive the run.
+func newUserClient(ctx context.Context, config *oauth2.Config, file auth.SavedToken, token *oauth2.Token) *Client {
+	// Token refreshes must outlive ctx, so only per-call contexts cancel
+	// requests
+	background := context.WithoutCancel(ctx)
+	return &Client{
+		httpClient:    oauth2.NewClient(background, file.TokenSource(background, config, token)),
+		authenticated: true,
+	}
+}
+
+// Authenticated reports whether the client acts on behalf of a user
+func (c *Client) Authenticated() bool {
+	return c.authenticated
+}
+
+// User is a Spotify account
+type User struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+// CurrentUser returns the logged-in user
+func (c *Client) CurrentUser(ctx context.Context) (User, error) {
+	var user User
+	if err := c.apiRequest(ctx, http.MethodGet, "/me", nil, &user); err != nil {
+		return User{}, fmt.Errorf("Spotify kullanıcısı alınamadı: %v", err)
+	}
+	return user, nil
+}
+
+// CurrentUserID returns the Spotify user ID of the logged-in user
+func (c *Client) CurrentUserID(ctx context.Context) (string, error) {
+	user, err := c.CurrentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// GetOwnPlaylists returns the playlists in the logged-in user's library
+func (c *Client) GetOwnPlaylists(ctx context.Context) ([]Playlist, error) {
+	var playlists []Playlist
+	next := "/me/playlists?limit=50"
+	for next != "" {
+		var page struct {
+			Items []struct {
+				ID          string `json:"id"`
+				Name        string `json:"name"`
+				Description string `json:"description"`
+				Public      bool   `json:"public"`
+				Owner       struct {
+					ID          string `json:"id"`
+					DisplayName string `json:"display_name"`
+				} `json:"owner"`
+				Tracks struct {
+					Total int `json:"total"`
+				} `json:"tracks"`
+			} `json:"items"`
+			Next string `json:"next"`
+		}
+		if err := c.apiRequest(ctx, http.MethodGet, next, nil, &page); err != nil {
+			return nil, fmt.Errorf("playlists alınamadı: %v", err)
+		}
+
+		for _, item := range page.Items {
+			playlists = append(playlists, Playlist{
+				ID:          item.ID,
+				Name:        item.Name,
+				Description: item.Description,
+				TrackCount:  item.Tracks.Total,
+				Public:      item.Public,
+				Owner:       item.Owner.DisplayName,
+				OwnerID:     item.Owner.ID,
+			})
+		}
+		next = strings.TrimPrefix(page.Next, apiBaseURL)
+	}
+	return playlists, nil
+}
+
+// PlaylistExists checks whether the user owns a playlist with the given name
+func (c *Client) PlaylistExists(ctx context.Context, name string) (bool, *Playlist, error) {
+	userID, err := c.CurrentUserID(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+	playlists, err := c.GetOwnPlaylists(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+
+	for _, playlist := range playlists {
+		if playlist.OwnerID == userID && strings.EqualFold(playlist.Name, name) {
+			return true, &playlist, nil
+		}
+	}
+	return false, nil, nil
+}
+
+// CreatePlaylist creates a private playlist for the logged-in user
+func (c *Client) CreatePlaylist(ctx context.Context, name, description string) (*Playlist, error) {
+	userID, err := c.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"public":      false,
+	}
+	var created struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.apiRequest(ctx, http.MethodPost, "/users/"+userID+"/playlists", request, &created); err != nil {
+		return nil, fmt.Errorf("playlist oluşturulamadı: %v", err)
+	}
+
+	return &Playlist{
+		ID:          created.ID,
+		Name:        created.Name,
+		Description: created.Description,
+		OwnerID:     userID,
+	}, nil
+}
+
+// AddTracksToPlaylist appends tracks to a playlist, in batches of at most
+// maxTracksPerRequest URIs
+func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID string, uris []string) error {
+	for start := 0; start < len(uris); start += maxTracksPerRequest {
+		end := start + maxTracksPerRequest
+		if end > len(uris) {
+			end = len(uris)
+		}
+
+		request := map[string]interface{}{"uris": uris[start:end]}
+		if err := c.apiRequest(ctx, http.MethodPost, "/playlists/"+playlistID+"/tracks", request, nil); err != nil {
+			return fmt.Errorf("tracks playlist'e eklenemedi: %v", err)
+		}
+	}
+	return nil
+}
+
+// apiRequest sends a JSON request to the Web API and decodes the response
+// into out when it is not nil
+func (c *Client) apiRequest(ctx context.Context, method, path string, body, out interface{}) error {
+	if !c.authenticated {
+		return fmt.Errorf("the Spotify Web API requires a login (set SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET)")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiBaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("request oluşturulamadı: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
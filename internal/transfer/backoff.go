@@ -0,0 +1,41 @@
+package transfer
+
+import (
+	"context"
+	"time"
+
+	"spotomusic/internal/logger"
+	"spotomusic/internal/youtube"
+)
+
+// retryPolicy controls how often failed YouTube calls are repeated
+type retryPolicy struct {
+	maxRetries int
+	// delay is the wait before the first retry, doubled after every retry
+	delay time.Duration
+}
+
+// callYouTube runs call after waiting for the rate limiter and repeats it
+// while it fails with a retryable error, up to the configured retries. The
+// last error is returned when ctx is cancelled during a backoff.
+func (s *Service) callYouTube(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		err := call()
+		if err == nil || attempt >= s.retry.maxRetries || !youtube.IsRetryable(err) {
+			return err
+		}
+
+		delay := s.retry.delay << attempt
+		logger.Debugf("YouTube request failed, retrying in %s (%d/%d): %v", delay, attempt+1, s.retry.maxRetries, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
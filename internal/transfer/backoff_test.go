@@ -0,0 +1,67 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+	"spotomusic/internal/config"
+)
+
+func TestCallYouTubeRetries(t *testing.T) {
+	service := NewService(&config.Config{
+		Transfer: config.TransferConfig{MaxRetries: 2, RetryDelay: 1},
+	}, Options{})
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", nil, 1, false},
+		{"recovers", []error{&googleapi.Error{Code: http.StatusServiceUnavailable}}, 2, false},
+		{"rate limited", []error{
+			&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}},
+		}, 2, false},
+		{"gives up", []error{
+			&googleapi.Error{Code: http.StatusInternalServerError},
+			&googleapi.Error{Code: http.StatusInternalServerError},
+			&googleapi.Error{Code: http.StatusInternalServerError},
+		}, 3, true},
+		{"not retryable", []error{&googleapi.Error{Code: http.StatusNotFound}}, 1, true},
+		{"quota", []error{
+			&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "quotaExceeded"}}},
+		}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := service.callYouTube(context.Background(), func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+				t.Errorf("calls = %d, err = %v; want %d calls, error %v", calls, err, tt.wantCalls, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallYouTubeWithoutConfig(t *testing.T) {
+	service := NewService(nil, Options{})
+	calls := 0
+	err := service.callYouTube(context.Background(), func() error {
+		calls++
+		return &googleapi.Error{Code: http.StatusServiceUnavailable}
+	})
+	if calls != 1 || !errors.As(err, new(*googleapi.Error)) {
+		t.Errorf("calls = %d, err = %v; want a single attempt", calls, err)
+	}
+}
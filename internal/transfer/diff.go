@@ -0,0 +1,210 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// PlaylistDiff describes how a YouTube mirror drifted from its Spotify
+// playlist
+type PlaylistDiff struct {
+	SourcePlaylistID  string                 `json:"source_playlist_id"`
+	YouTubePlaylistID string                 `json:"youtube_playlist_id"`
+	SpotifyTracks     int                    `json:"spotify_tracks"`
+	YouTubeVideos     int                    `json:"youtube_videos"`
+	Paired            int                    `json:"paired"`
+	OnlyOnSpotify     []DiffTrack            `json:"only_on_spotify"`
+	OnlyOnYouTube     []youtube.PlaylistItem `json:"only_on_youtube"`
+	// Pairs lists every paired track in Spotify order
+	Pairs []DiffPair `json:"-"`
+	// Moved are the pairs that would have to move to restore the Spotify
+	// order
+	Moved []DiffPair `json:"moved"`
+}
+
+// InSync reports whether the mirror matches the playlist exactly
+func (d PlaylistDiff) InSync() bool {
+	return len(d.OnlyOnSpotify) == 0 && len(d.OnlyOnYouTube) == 0 && len(d.Moved) == 0
+}
+
+// DiffTrack is a Spotify track at its 1-based playlist position
+type DiffTrack struct {
+	Position int           `json:"position"`
+	Track    spotify.Track `json:"track"`
+}
+
+// DiffPair is a Spotify track and the YouTube item mirroring it
+type DiffPair struct {
+	DiffTrack
+	Item youtube.PlaylistItem `json:"item"`
+	// Fuzzy is set when the pair was found by title instead of the
+	// recorded transfer
+	Fuzzy bool `json:"fuzzy"`
+}
+
+// DiffPlaylist compares a Spotify playlist with the YouTube playlist it was
+// transferred to. mirror is the latest recorded transfer of the playlist;
+// its track to video mapping is used first and title matching fills the
+// gaps.
+func (s *Service) DiffPlaylist(ctx context.Context, playlistID string, mirror TransferResult) (PlaylistDiff, error) {
+	if mirror.YouTubePlaylist == nil || mirror.YouTubePlaylist.ID == "" {
+		return PlaylistDiff{}, fmt.Errorf("no YouTube playlist recorded for %s", playlistID)
+	}
+	if err := s.initializeClients(ctx, true); err != nil {
+		return PlaylistDiff{}, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	tracks, err := s.source.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		return PlaylistDiff{}, fmt.Errorf("playlist tracks alınamadı: %v", err)
+	}
+	items, err := s.youtubeClient.GetPlaylistItems(ctx, mirror.YouTubePlaylist.ID)
+	if err != nil {
+		return PlaylistDiff{}, err
+	}
+
+	diff := DiffPlaylist(tracks, mirror.Tracks, items)
+	diff.SourcePlaylistID = playlistID
+	diff.YouTubePlaylistID = mirror.YouTubePlaylist.ID
+	return diff, nil
+}
+
+// DiffPlaylist pairs Spotify tracks with YouTube items, first through the
+// recorded track results and then by fuzzy title matching, and reports the
+// unpaired entries on both sides and the pairs that are out of order
+func DiffPlaylist(tracks []spotify.Track, recorded []TrackResult, items []youtube.PlaylistItem) PlaylistDiff {
+	diff := PlaylistDiff{
+		SpotifyTracks: len(tracks),
+		YouTubeVideos: len(items),
+		OnlyOnSpotify: []DiffTrack{},
+		OnlyOnYouTube: []youtube.PlaylistItem{},
+		Moved:         []DiffPair{},
+	}
+
+	mapping := make(map[string]string)
+	for _, result := range recorded {
+		if !result.Failed() && result.Video != nil {
+			mapping[trackKey(result.Track)] = result.Video.ID
+		}
+	}
+
+	used := make([]bool, len(items))
+	itemFor := make([]int, len(tracks))
+
+	// Recorded mapping first, so fuzzy matching cannot steal those items
+	for i, track := range tracks {
+		itemFor[i] = -1
+		videoID, ok := mapping[trackKey(track)]
+		if !ok {
+			continue
+		}
+		for j, item := range items {
+			if !used[j] && item.Video.ID == videoID {
+				itemFor[i], used[j] = j, true
+				break
+			}
+		}
+	}
+
+	fuzzy := make([]bool, len(tracks))
+	for i, track := range tracks {
+		if itemFor[i] >= 0 {
+			continue
+		}
+		best, bestScore := -1, 0
+		for j, item := range items {
+			if used[j] {
+				continue
+			}
+			score := scoreTitles(track.Name, track.Artist, item.Video.Title, item.Video.ChannelName)
+			if score > 0 {
+				score -= unwantedPenalty(track.Name, item.Video.Title)
+			}
+			if score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best >= 0 && bestScore >= minMatchScore {
+			itemFor[i], used[best], fuzzy[i] = best, true, true
+		}
+	}
+
+	var pairs []DiffPair
+	for i, track := range tracks {
+		entry := DiffTrack{Position: i + 1, Track: track}
+		if itemFor[i] < 0 {
+			diff.OnlyOnSpotify = append(diff.OnlyOnSpotify, entry)
+			continue
+		}
+		pairs = append(pairs, DiffPair{DiffTrack: entry, Item: items[itemFor[i]], Fuzzy: fuzzy[i]})
+	}
+	for j, item := range items {
+		if !used[j] {
+			diff.OnlyOnYouTube = append(diff.OnlyOnYouTube, item)
+		}
+	}
+	diff.Pairs = pairs
+	diff.Paired = len(pairs)
+
+	// The longest run of pairs already in YouTube order stays put; every
+	// other pair is out of order
+	positions := make([]int, len(pairs))
+	for i, pair := range pairs {
+		positions[i] = pair.Item.Position
+	}
+	inOrder := longestIncreasing(positions)
+	for i, pair := range pairs {
+		if !inOrder[i] {
+			diff.Moved = append(diff.Moved, pair)
+		}
+	}
+
+	return diff
+}
+
+// trackKey identifies a track by artist and name
+func trackKey(track spotify.Track) string {
+	return strings.ToLower(track.Artist + "\x00" + track.Name)
+}
+
+// longestIncreasing marks the elements of one longest strictly increasing
+// subsequence of values
+func longestIncreasing(values []int) []bool {
+	// tails[k] is the index of the smallest tail of an increasing
+	// subsequence of length k+1; prev links each element to its predecessor
+	var tails []int
+	prev := make([]int, len(values))
+	for i, value := range values {
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if values[tails[mid]] < value {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		prev[i] = -1
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		}
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+
+	marked := make([]bool, len(values))
+	if len(tails) == 0 {
+		return marked
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		marked[i] = true
+	}
+	return marked
+}
@@ -0,0 +1,77 @@
+package transfer
+
+import (
+	"testing"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+func playlistItem(position int, videoID, title, channel string) youtube.PlaylistItem {
+	return youtube.PlaylistItem{
+		ID:       "item-" + videoID,
+		Position: position,
+		Video:    youtube.YouTubeVideo{ID: videoID, Title: title, ChannelName: channel},
+	}
+}
+
+func TestDiffPlaylist(t *testing.T) {
+	tracks := []spotify.Track{
+		{Artist: "Ed Sheeran", Name: "Shape of You"},
+		{Artist: "Dua Lipa", Name: "Levitating"},
+		{Artist: "Adele", Name: "Hello"},
+		{Artist: "Queen", Name: "Bohemian Rhapsody"},
+		{Artist: "Nobody", Name: "New Song"},
+	}
+	recorded := []TrackResult{
+		{Track: tracks[0], Video: &youtube.YouTubeVideo{ID: "v1"}, Status: StatusMatched},
+		{Track: tracks[1], Video: &youtube.YouTubeVideo{ID: "v2"}, Status: StatusMatched},
+		{Track: tracks[2], Video: &youtube.YouTubeVideo{ID: "v3"}, Status: StatusMatched},
+	}
+	// Hello was moved to the front; Bohemian Rhapsody was added by hand
+	items := []youtube.PlaylistItem{
+		playlistItem(0, "v3", "Hello", "Adele - Topic"),
+		playlistItem(1, "v1", "Shape of You", "Ed Sheeran - Topic"),
+		playlistItem(2, "v2", "Levitating", "Dua Lipa - Topic"),
+		playlistItem(3, "v9", "Queen – Bohemian Rhapsody (Official Video Remastered)", "Queen Official"),
+		playlistItem(4, "v7", "Some Other Video", "Someone"),
+	}
+
+	diff := DiffPlaylist(tracks, recorded, items)
+
+	if diff.Paired != 4 {
+		t.Errorf("Paired = %d, want 4", diff.Paired)
+	}
+	if len(diff.OnlyOnSpotify) != 1 || diff.OnlyOnSpotify[0].Track.Name != "New Song" || diff.OnlyOnSpotify[0].Position != 5 {
+		t.Errorf("OnlyOnSpotify = %+v", diff.OnlyOnSpotify)
+	}
+	if len(diff.OnlyOnYouTube) != 1 || diff.OnlyOnYouTube[0].Video.ID != "v7" {
+		t.Errorf("OnlyOnYouTube = %+v", diff.OnlyOnYouTube)
+	}
+	if len(diff.Moved) != 1 || diff.Moved[0].Track.Name != "Hello" {
+		t.Errorf("Moved = %+v", diff.Moved)
+	}
+	if diff.InSync() {
+		t.Error("InSync() = true for a drifted playlist")
+	}
+}
+
+func TestDiffPlaylistFuzzyPairs(t *testing.T) {
+	tracks := []spotify.Track{{Artist: "Queen", Name: "Bohemian Rhapsody"}}
+	items := []youtube.PlaylistItem{playlistItem(0, "v9", "Queen - Bohemian Rhapsody", "Queen Official")}
+
+	diff := DiffPlaylist(tracks, nil, items)
+	if !diff.InSync() || diff.Paired != 1 {
+		t.Fatalf("Expected a fuzzy pair, got %+v", diff)
+	}
+}
+
+func TestLongestIncreasing(t *testing.T) {
+	marked := longestIncreasing([]int{2, 0, 1, 3})
+	want := []bool{false, true, true, true}
+	for i := range want {
+		if marked[i] != want[i] {
+			t.Fatalf("longestIncreasing() = %v, want %v", marked, want)
+		}
+	}
+}
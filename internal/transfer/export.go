@@ -0,0 +1,271 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"spotomusic/internal/youtube"
+)
+
+// ExportFormat is a file format that matched tracks can be written to
+type ExportFormat string
+
+const (
+	ExportM3U  ExportFormat = "m3u"
+	ExportXSPF ExportFormat = "xspf"
+	ExportJSON ExportFormat = "json"
+)
+
+// Destination is where a transfer writes its matches: a YouTube playlist
+// (the zero value) or a playlist file on disk
+type Destination struct {
+	Format ExportFormat
+	Path   string
+}
+
+// IsFile reports whether the destination is a file instead of YouTube
+func (d Destination) IsFile() bool {
+	return d.Format != ""
+}
+
+// String returns the destination in the --to flag syntax
+func (d Destination) String() string {
+	if !d.IsFile() {
+		return "youtube"
+	}
+	return string(d.Format) + ":" + d.Path
+}
+
+// ParseDestination parses a --to value: "youtube", "m3u:path.m3u8",
+// "xspf:path.xspf" or "json:path.json"
+func ParseDestination(value string) (Destination, error) {
+	if value == "" || strings.EqualFold(value, "youtube") {
+		return Destination{}, nil
+	}
+
+	format, path, ok := strings.Cut(value, ":")
+	if !ok || path == "" {
+		return Destination{}, fmt.Errorf("invalid destination %q (expected youtube, m3u:<file>, xspf:<file> or json:<file>)", value)
+	}
+	switch ExportFormat(strings.ToLower(format)) {
+	case ExportM3U, "m3u8":
+		return Destination{Format: ExportM3U, Path: path}, nil
+	case ExportXSPF:
+		return Destination{Format: ExportXSPF, Path: path}, nil
+	case ExportJSON:
+		return Destination{Format: ExportJSON, Path: path}, nil
+	}
+	return Destination{}, fmt.Errorf("unknown export format %q (expected m3u, xspf or json)", format)
+}
+
+// ExportedPlaylist is the JSON export of one transferred playlist
+type ExportedPlaylist struct {
+	Name             string          `json:"name"`
+	SourcePlaylistID string          `json:"source_playlist_id"`
+	Tracks           []ExportedTrack `json:"tracks"`
+}
+
+// ExportedTrack is a matched track with the video it resolved to
+type ExportedTrack struct {
+	Position int    `json:"position"`
+	Artist   string `json:"artist"`
+	Title    string `json:"title"`
+	Album    string `json:"album,omitempty"`
+	// DurationSeconds is -1 when unknown
+	DurationSeconds int    `json:"duration_seconds"`
+	VideoID         string `json:"video_id"`
+	VideoTitle      string `json:"video_title"`
+	Channel         string `json:"channel"`
+	URL             string `json:"url"`
+}
+
+// exportPlaylists keeps only the matched tracks of each result
+func exportPlaylists(results []TransferResult) []ExportedPlaylist {
+	var playlists []ExportedPlaylist
+	for _, result := range results {
+		playlist := ExportedPlaylist{
+			Name:             result.PlaylistName,
+			SourcePlaylistID: result.SourcePlaylistID,
+			Tracks:           []ExportedTrack{},
+		}
+		for _, track := range result.Tracks {
+			if track.Failed() || track.Video == nil {
+				continue
+			}
+			playlist.Tracks = append(playlist.Tracks, ExportedTrack{
+				Position:        track.Position,
+				Artist:          track.Track.Artist,
+				Title:           track.Track.Name,
+				Album:           track.Track.Album,
+				DurationSeconds: durationSeconds(track),
+				VideoID:         track.Video.ID,
+				VideoTitle:      track.Video.Title,
+				Channel:         track.Video.ChannelName,
+				URL:             track.Video.URL,
+			})
+		}
+		playlists = append(playlists, playlist)
+	}
+	return playlists
+}
+
+// durationSeconds prefers the video length, which is what a player will
+// see, and falls back to the Spotify track length
+func durationSeconds(track TrackResult) int {
+	if track.Video != nil && track.Video.Duration != "" {
+		if duration, err := youtube.ParseDuration(track.Video.Duration); err == nil {
+			return int(duration.Seconds())
+		}
+	}
+	if track.Track.Duration > 0 {
+		return track.Track.Duration / 1000
+	}
+	return -1
+}
+
+// WriteExport writes the matched tracks of results to the destination file
+func WriteExport(destination Destination, results []TransferResult) error {
+	var write func(io.Writer, []ExportedPlaylist) error
+	switch destination.Format {
+	case ExportM3U:
+		write = writeM3U
+	case ExportXSPF:
+		write = writeXSPF
+	case ExportJSON:
+		write = writeExportJSON
+	default:
+		return fmt.Errorf("destination %s is not a file", destination)
+	}
+
+	file, err := os.Create(destination.Path)
+	if err != nil {
+		return fmt.Errorf("export dosyası oluşturulamadı: %v", err)
+	}
+	defer file.Close()
+
+	if err := write(file, exportPlaylists(results)); err != nil {
+		return fmt.Errorf("export yazılamadı: %v", err)
+	}
+	return file.Close()
+}
+
+// writeM3U writes an extended M3U8 playlist. Several playlists end up in one
+// file, each starting with an #EXTGRP group.
+func writeM3U(w io.Writer, playlists []ExportedPlaylist) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	if len(playlists) == 1 {
+		fmt.Fprintf(&b, "#PLAYLIST:%s\n", m3uEscape(playlists[0].Name))
+	}
+	for _, playlist := range playlists {
+		if len(playlists) > 1 {
+			fmt.Fprintf(&b, "#EXTGRP:%s\n", m3uEscape(playlist.Name))
+		}
+		for _, track := range playlist.Tracks {
+			fmt.Fprintf(&b, "#EXTINF:%d,%s - %s\n", track.DurationSeconds, m3uEscape(track.Artist), m3uEscape(track.Title))
+			fmt.Fprintf(&b, "%s\n", track.URL)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// m3uEscape keeps a value on a single #EXT line
+func m3uEscape(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Version string      `xml:"version,attr"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title"`
+	Creator  string `xml:"creator"`
+	Album    string `xml:"album,omitempty"`
+	// Duration is in milliseconds
+	Duration int `xml:"duration,omitempty"`
+}
+
+// writeXSPF writes an XSPF playlist; several playlists are concatenated
+func writeXSPF(w io.Writer, playlists []ExportedPlaylist) error {
+	playlist := xspfPlaylist{
+		Version: "1",
+		XMLNS:   "http://xspf.org/ns/0/",
+		Title:   "SpoToMusic export",
+	}
+	if len(playlists) == 1 {
+		playlist.Title = playlists[0].Name
+	}
+	for _, source := range playlists {
+		for _, track := range source.Tracks {
+			entry := xspfTrack{
+				Location: track.URL,
+				Title:    track.Title,
+				Creator:  track.Artist,
+				Album:    track.Album,
+			}
+			if track.DurationSeconds > 0 {
+				entry.Duration = track.DurationSeconds * 1000
+			}
+			playlist.Tracks = append(playlist.Tracks, entry)
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(playlist); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeExportJSON writes the playlists as indented JSON
+func writeExportJSON(w io.Writer, playlists []ExportedPlaylist) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(playlists)
+}
+
+// fillDurations looks up the length of every matched video so exports can
+// carry real durations. Failures only cost the durations.
+func (s *Service) fillDurations(ctx context.Context, result *TransferResult) {
+	var ids []string
+	for _, track := range result.Tracks {
+		if track.Video != nil && track.Video.Duration == "" {
+			ids = append(ids, track.Video.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	details, err := s.youtubeClient.GetVideoDetails(ctx, ids)
+	if err != nil {
+		fmt.Printf("Warning: video durations unavailable: %v\n", err)
+		return
+	}
+	for i := range result.Tracks {
+		video := result.Tracks[i].Video
+		if video == nil {
+			continue
+		}
+		if detail, ok := details[video.ID]; ok && detail.Duration > 0 {
+			video.Duration = fmt.Sprintf("PT%dS", int(detail.Duration.Seconds()))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Destination
+		wantErr bool
+	}{
+		{value: "", want: Destination{}},
+		{value: "youtube", want: Destination{}},
+		{value: "m3u:out.m3u8", want: Destination{Format: ExportM3U, Path: "out.m3u8"}},
+		{value: "M3U8:C:/music/out.m3u8", want: Destination{Format: ExportM3U, Path: "C:/music/out.m3u8"}},
+		{value: "xspf:out.xspf", want: Destination{Format: ExportXSPF, Path: "out.xspf"}},
+		{value: "json:out.json", want: Destination{Format: ExportJSON, Path: "out.json"}},
+		{value: "json:", wantErr: true},
+		{value: "pls:out.pls", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDestination(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDestination(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDestination(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteM3U(t *testing.T) {
+	results := testReport().Playlists
+	results[0].Tracks[0].Video.Duration = "PT3M54S"
+
+	var buf bytes.Buffer
+	if err := writeM3U(&buf, exportPlaylists(results)); err != nil {
+		t.Fatalf("writeM3U() error = %v", err)
+	}
+
+	want := "#EXTM3U\n" +
+		"#PLAYLIST:Road | Trip\n" +
+		"#EXTINF:234,Ed Sheeran - Shape of You\n" +
+		"https://www.youtube.com/watch?v=JGwWNGJdvx8\n"
+	if buf.String() != want {
+		t.Errorf("writeM3U() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteExportFiles(t *testing.T) {
+	dir := t.TempDir()
+	results := testReport().Playlists
+
+	xspfPath := filepath.Join(dir, "out.xspf")
+	if err := WriteExport(Destination{Format: ExportXSPF, Path: xspfPath}, results); err != nil {
+		t.Fatalf("WriteExport(xspf) error = %v", err)
+	}
+	data, _ := os.ReadFile(xspfPath)
+	var playlist xspfPlaylist
+	if err := xml.Unmarshal(data, &playlist); err != nil {
+		t.Fatalf("XSPF is not valid XML: %v", err)
+	}
+	if len(playlist.Tracks) != 1 || playlist.Tracks[0].Creator != "Ed Sheeran" {
+		t.Errorf("Unexpected XSPF tracks: %+v", playlist.Tracks)
+	}
+	if !strings.Contains(string(data), `xmlns="http://xspf.org/ns/0/"`) {
+		t.Errorf("XSPF misses its namespace:\n%s", data)
+	}
+
+	jsonPath := filepath.Join(dir, "out.json")
+	if err := WriteExport(Destination{Format: ExportJSON, Path: jsonPath}, results); err != nil {
+		t.Fatalf("WriteExport(json) error = %v", err)
+	}
+	data, _ = os.ReadFile(jsonPath)
+	var playlists []ExportedPlaylist
+	if err := json.Unmarshal(data, &playlists); err != nil {
+		t.Fatalf("JSON export is not valid: %v", err)
+	}
+	if len(playlists) != 1 || len(playlists[0].Tracks) != 1 || playlists[0].Tracks[0].DurationSeconds != -1 {
+		t.Errorf("Unexpected JSON export: %+v", playlists)
+	}
+}
@@ -0,0 +1,200 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"spotomusic/internal/spotify"
+)
+
+// Manifest lists the Spotify playlists to transfer or keep in sync, each
+// with its own destination and matching settings
+type Manifest struct {
+	// Defaults apply to every playlist that does not set a value itself
+	Defaults  ManifestEntry   `yaml:"defaults"`
+	Playlists []ManifestEntry `yaml:"playlists"`
+}
+
+// ManifestEntry describes one mirrored playlist
+type ManifestEntry struct {
+	// Source is a Spotify playlist ID, URI or link
+	Source string `yaml:"source"`
+	// Name of the YouTube playlist, a template like Description; empty
+	// keeps the Spotify name
+	Name string `yaml:"name"`
+	// Privacy of a created playlist: public, unlisted or private
+	Privacy string `yaml:"privacy"`
+	// Description is a template for the description of a created playlist,
+	// see TemplateData
+	Description string `yaml:"description"`
+	// Sync selects how sync adds new tracks: append, ordered or off
+	Sync SyncMode `yaml:"sync"`
+	// Prune makes sync remove videos whose track left the playlist
+	Prune *bool `yaml:"prune"`
+	// Metadata makes sync update the name and description of the YouTube
+	// playlist when the Spotify playlist changed; unset means true
+	Metadata *bool         `yaml:"metadata"`
+	Match    ManifestMatch `yaml:"match"`
+}
+
+// ManifestMatch overrides the matching options for one playlist. Channel
+// lists extend the configured ones.
+type ManifestMatch struct {
+	Prefer        string   `yaml:"prefer"`
+	AllowChannels []string `yaml:"allow_channels"`
+	DenyChannels  []string `yaml:"deny_channels"`
+}
+
+// LoadManifest reads a manifest file, applies its defaults to every
+// playlist and validates the result
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest okunamadı: %v", err)
+	}
+
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("manifest parse edilemedi: %v", err)
+	}
+	if len(manifest.Playlists) == 0 {
+		return nil, fmt.Errorf("manifest %s lists no playlists", path)
+	}
+
+	seen := make(map[string]bool)
+	for i := range manifest.Playlists {
+		entry := manifest.Playlists[i].withDefaults(manifest.Defaults)
+		if err := entry.validate(); err != nil {
+			return nil, fmt.Errorf("manifest playlist %d (%s): %v", i+1, entry.Source, err)
+		}
+		if seen[entry.PlaylistID()] {
+			return nil, fmt.Errorf("manifest playlist %d: %s is listed twice", i+1, entry.Source)
+		}
+		seen[entry.PlaylistID()] = true
+		manifest.Playlists[i] = entry
+	}
+	return &manifest, nil
+}
+
+// withDefaults fills the unset values of an entry from defaults
+func (e ManifestEntry) withDefaults(defaults ManifestEntry) ManifestEntry {
+	if e.Privacy == "" {
+		e.Privacy = defaults.Privacy
+	}
+	if e.Description == "" {
+		e.Description = defaults.Description
+	}
+	if e.Sync == "" {
+		e.Sync = defaults.Sync
+	}
+	if e.Prune == nil {
+		e.Prune = defaults.Prune
+	}
+	if e.Metadata == nil {
+		e.Metadata = defaults.Metadata
+	}
+	if e.Match.Prefer == "" {
+		e.Match.Prefer = defaults.Match.Prefer
+	}
+	e.Match.AllowChannels = append(append([]string{}, defaults.Match.AllowChannels...), e.Match.AllowChannels...)
+	e.Match.DenyChannels = append(append([]string{}, defaults.Match.DenyChannels...), e.Match.DenyChannels...)
+	return e
+}
+
+// validate checks an entry and normalizes its values
+func (e *ManifestEntry) validate() error {
+	if e.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+	playlist := e.Playlist()
+	if err := playlist.Validate(); err != nil {
+		return err
+	}
+	e.Privacy = playlist.Privacy
+	var err error
+	if e.Sync, err = ParseSyncMode(string(e.Sync)); err != nil {
+		return err
+	}
+	if e.Match.Prefer != "" {
+		prefer, err := ParsePreference(e.Match.Prefer)
+		if err != nil {
+			return err
+		}
+		e.Match.Prefer = string(prefer)
+	}
+	return nil
+}
+
+// PlaylistID returns the Spotify playlist ID of the entry
+func (e ManifestEntry) PlaylistID() string {
+	return spotify.ParsePlaylistID(e.Source)
+}
+
+// Playlist returns the destination settings of the entry
+func (e ManifestEntry) Playlist() PlaylistOptions {
+	return PlaylistOptions{
+		Title:       e.Name,
+		Description: e.Description,
+		Privacy:     e.Privacy,
+	}
+}
+
+// SyncOptions returns how sync updates the mirror of the entry
+func (e ManifestEntry) SyncOptions(dryRun bool) SyncOptions {
+	return SyncOptions{
+		Mode:     e.Sync,
+		Prune:    e.Prune != nil && *e.Prune,
+		Metadata: e.Metadata == nil || *e.Metadata,
+		Playlist: e.Playlist(),
+		DryRun:   dryRun,
+	}
+}
+
+// forEntry returns a service sharing the clients and rate limit of s with
+// the matching options of a manifest entry. Entries are validated, so the
+// preference parses.
+func (s *Service) forEntry(entry ManifestEntry) *Service {
+	entryService := *s
+	if entry.Match.Prefer != "" {
+		entryService.match.Prefer, _ = ParsePreference(entry.Match.Prefer)
+	}
+	entryService.match.AllowChannels = append(append([]string{}, s.match.AllowChannels...), entry.Match.AllowChannels...)
+	entryService.match.DenyChannels = append(append([]string{}, s.match.DenyChannels...), entry.Match.DenyChannels...)
+	return &entryService
+}
+
+// TransferManifest transfers every playlist of a manifest with its own
+// settings. A failing playlist does not stop the others.
+func (s *Service) TransferManifest(ctx context.Context, manifest *Manifest, dryRun bool) ([]TransferResult, error) {
+	if err := s.initializeClients(ctx, dryRun); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	fmt.Printf("Found %d playlists in the manifest\n", len(manifest.Playlists))
+
+	var totalResults []TransferResult
+	for i, entry := range manifest.Playlists {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("\n[%d/%d] Processing: %s\n", i+1, len(manifest.Playlists), entry.Source)
+
+		results, err := s.forEntry(entry).transferPlaylist(ctx, entry.PlaylistID(), "", entry.Playlist(), dryRun)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		totalResults = append(totalResults, results...)
+	}
+
+	s.printSummary(totalResults)
+
+	return totalResults, ctx.Err()
+}
@@ -0,0 +1,87 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "playlists.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	path := writeManifest(t, `
+defaults:
+  privacy: unlisted
+  prune: true
+  match:
+    deny_channels: ["*nightcore*"]
+playlists:
+  - source: https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc
+    name: Today's Hits
+    description: "{{.Name}} by {{.Owner}}"
+    sync: ordered
+    match:
+      prefer: topic
+      deny_channels: ["UCxxxx"]
+  - source: spotify:playlist:5ABHKGoOzxkaa28ttQV9sE
+    privacy: Public
+    prune: false
+    metadata: false
+`)
+
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Playlists) != 2 {
+		t.Fatalf("got %d playlists", len(manifest.Playlists))
+	}
+
+	first := manifest.Playlists[0]
+	if first.PlaylistID() != "37i9dQZF1DXcBWIGoYBM5M" || first.Privacy != "unlisted" || first.Sync != SyncOrdered {
+		t.Errorf("first = %+v", first)
+	}
+	if first.Match.Prefer != "topic" || strings.Join(first.Match.DenyChannels, ",") != "*nightcore*,UCxxxx" {
+		t.Errorf("first.Match = %+v", first.Match)
+	}
+	if options := first.SyncOptions(false); !options.Prune || !options.Metadata || options.Playlist.Title != "Today's Hits" {
+		t.Errorf("first.SyncOptions() = %+v, want prune from the defaults", options)
+	}
+
+	second := manifest.Playlists[1]
+	if second.PlaylistID() != "5ABHKGoOzxkaa28ttQV9sE" || second.Privacy != "public" || second.Sync != SyncAppend {
+		t.Errorf("second = %+v", second)
+	}
+	if options := second.SyncOptions(false); options.Prune || options.Metadata {
+		t.Errorf("second.SyncOptions() = %+v, want prune and metadata off", options)
+	}
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	tests := map[string]string{
+		"no playlists":      "defaults:\n  privacy: public\n",
+		"missing source":    "playlists:\n  - name: x\n",
+		"bad privacy":       "playlists:\n  - source: a\n    privacy: friends\n",
+		"bad sync":          "playlists:\n  - source: a\n    sync: mirror\n",
+		"bad preference":    "playlists:\n  - source: a\n    match:\n      prefer: loud\n",
+		"unknown field":     "playlists:\n  - source: a\n    colour: blue\n",
+		"bad template":      "playlists:\n  - source: a\n    description: \"{{.Nmae}}\"\n",
+		"listed twice":      "playlists:\n  - source: a\n  - source: spotify:playlist:a\n",
+		"unclosed template": "playlists:\n  - source: a\n    description: \"{{.Name\"\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadManifest(writeManifest(t, content)); err == nil {
+				t.Error("LoadManifest() should fail")
+			}
+		})
+	}
+}
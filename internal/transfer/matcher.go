@@ -0,0 +1,220 @@
+package transfer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// Preference controls which kind of upload the matcher favours
+type Preference string
+
+const (
+	// PreferTopic favours "<Artist> - Topic" auto-generated album audio
+	PreferTopic Preference = "topic"
+	// PreferOfficialVideo favours official music videos
+	PreferOfficialVideo Preference = "official_video"
+	// PreferAny applies no bias beyond title and artist similarity
+	PreferAny Preference = "any"
+)
+
+// ParsePreference validates a preference value from config or flags
+func ParsePreference(value string) (Preference, error) {
+	switch Preference(strings.ToLower(strings.TrimSpace(value))) {
+	case PreferTopic:
+		return PreferTopic, nil
+	case PreferOfficialVideo:
+		return PreferOfficialVideo, nil
+	case PreferAny, "":
+		return PreferAny, nil
+	}
+	return "", fmt.Errorf("unknown match preference %q (expected topic, official_video or any)", value)
+}
+
+// MatchOptions configures how YouTube results are scored against a track
+type MatchOptions struct {
+	Prefer Preference `json:"prefer"`
+	// AllowChannels are always trusted; entries are channel IDs or
+	// case-insensitive name patterns such as "*VEVO"
+	AllowChannels []string `json:"allow_channels,omitempty"`
+	// DenyChannels are never picked, using the same entry format
+	DenyChannels []string `json:"deny_channels,omitempty"`
+}
+
+const (
+	// minMatchScore is the lowest score a video needs to be accepted
+	minMatchScore = 50
+
+	scoreTitleMatch  = 50
+	scoreArtistMatch = 30
+	scorePreferred   = 30
+	scoreTopicBonus  = 5
+	scoreAllowed     = 100
+	penaltyUnwanted  = 20
+)
+
+// unwantedMarkers are words that usually mean a different recording
+var unwantedMarkers = []string{"cover", "karaoke", "instrumental", "live", "remix", "sped up", "slowed", "8d audio", "reaction"}
+
+// buildSearchQuery builds a search query for YouTube
+func (s *Service) buildSearchQuery(track spotify.Track) string {
+	// Clean up the query
+	query := fmt.Sprintf("%s %s", track.Artist, track.Name)
+
+	// Remove common words that might interfere with search
+	query = strings.ReplaceAll(query, "ft.", "")
+	query = strings.ReplaceAll(query, "feat.", "")
+	query = strings.ReplaceAll(query, "featuring", "")
+
+	// Remove extra spaces
+	query = strings.Join(strings.Fields(query), " ")
+
+	return query
+}
+
+// findBestMatch finds the best matching YouTube video
+func (s *Service) findBestMatch(track spotify.Track, videos []youtube.YouTubeVideo) *youtube.YouTubeVideo {
+	match, _ := s.bestMatch(track, videos)
+	return match
+}
+
+// bestMatch returns the highest scoring video and its score, or nil when
+// no candidate reaches minMatchScore
+func (s *Service) bestMatch(track spotify.Track, videos []youtube.YouTubeVideo) (*youtube.YouTubeVideo, int) {
+	var best *youtube.YouTubeVideo
+	bestScore := 0
+
+	for i := range videos {
+		score := s.scoreVideo(track, videos[i])
+		if score > bestScore {
+			best = &videos[i]
+			bestScore = score
+		}
+	}
+
+	if bestScore < minMatchScore {
+		return nil, bestScore
+	}
+	return best, bestScore
+}
+
+// scoreVideo rates how well a video matches a track
+func (s *Service) scoreVideo(track spotify.Track, video youtube.YouTubeVideo) int {
+	if channelListed(s.match.DenyChannels, video) {
+		return 0
+	}
+
+	score := scoreTitles(track.Name, track.Artist, video.Title, video.ChannelName)
+	if score == 0 {
+		return 0
+	}
+
+	// Trusted channels outrank everything else that matches the title
+	if channelListed(s.match.AllowChannels, video) {
+		return score + scoreAllowed
+	}
+
+	score -= unwantedPenalty(track.Name, video.Title)
+
+	switch s.match.Prefer {
+	case PreferTopic:
+		if isTopicChannel(video) {
+			score += scorePreferred
+		}
+	case PreferOfficialVideo:
+		if isOfficialVideo(video) {
+			score += scorePreferred
+		}
+	default:
+		// Topic uploads are the studio recording, so they win ties
+		if isTopicChannel(video) {
+			score += scoreTopicBonus
+		}
+	}
+
+	return score
+}
+
+// scoreTitles is the direction-independent part of the matcher. The wanted
+// title must appear in the candidate title; the wanted artist may appear in
+// either the candidate title or its credits (channel or artist names).
+func scoreTitles(wantTitle, wantArtist, candidateTitle, credits string) int {
+	wantTitle = strings.ToLower(wantTitle)
+	candidateTitle = strings.ToLower(candidateTitle)
+	credits = strings.ToLower(credits)
+
+	if wantTitle == "" || !strings.Contains(candidateTitle, wantTitle) {
+		return 0
+	}
+	score := scoreTitleMatch
+
+	artist := strings.ToLower(primaryArtist(wantArtist))
+	if artist != "" && (strings.Contains(candidateTitle, artist) || strings.Contains(credits, artist)) {
+		score += scoreArtistMatch
+	}
+	return score
+}
+
+// unwantedPenalty penalises candidates marked as a different recording
+// (cover, live, remix, ...) unless the wanted title carries the same marker
+func unwantedPenalty(wantTitle, candidateTitle string) int {
+	wantTitle = strings.ToLower(wantTitle)
+	candidateTitle = strings.ToLower(candidateTitle)
+	for _, marker := range unwantedMarkers {
+		if strings.Contains(candidateTitle, marker) && !strings.Contains(wantTitle, marker) {
+			return penaltyUnwanted
+		}
+	}
+	return 0
+}
+
+// primaryArtist strips featured artists from an artist string
+func primaryArtist(artist string) string {
+	for _, sep := range []string{" ft. ", " feat. ", " featuring ", ", "} {
+		if idx := strings.Index(strings.ToLower(artist), sep); idx >= 0 {
+			artist = artist[:idx]
+		}
+	}
+	return strings.TrimSpace(artist)
+}
+
+// channelListed reports whether a video's channel matches any entry, either
+// by exact channel ID or by a case-insensitive channel name pattern
+func channelListed(entries []string, video youtube.YouTubeVideo) bool {
+	name := strings.ToLower(video.ChannelName)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if video.ChannelID != "" && entry == video.ChannelID {
+			return true
+		}
+		pattern := strings.ToLower(entry)
+		if pattern == name {
+			return true
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isTopicChannel reports whether a video comes from an auto-generated
+// "<Artist> - Topic" channel
+func isTopicChannel(video youtube.YouTubeVideo) bool {
+	return strings.HasSuffix(strings.ToLower(video.ChannelName), " - topic")
+}
+
+// isOfficialVideo reports whether a video looks like an official music video
+func isOfficialVideo(video youtube.YouTubeVideo) bool {
+	title := strings.ToLower(video.Title)
+	channel := strings.ToLower(video.ChannelName)
+	return strings.Contains(title, "official video") ||
+		strings.Contains(title, "official music video") ||
+		strings.HasSuffix(channel, "vevo")
+}
@@ -76,3 +76,108 @@ func TestFindBestMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestFindBestMatchPreference(t *testing.T) {
+	track := spotify.Track{
+		Name:   "Shape of You",
+		Artist: "Ed Sheeran",
+	}
+	videos := []youtube.YouTubeVideo{
+		{
+			ID:          "video",
+			Title:       "Ed Sheeran - Shape of You (Official Music Video)",
+			ChannelName: "Ed Sheeran",
+		},
+		{
+			ID:          "topic",
+			Title:       "Shape of You",
+			ChannelName: "Ed Sheeran - Topic",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		prefer   Preference
+		expectID string
+	}{
+		{name: "Prefer topic", prefer: PreferTopic, expectID: "topic"},
+		{name: "Prefer official video", prefer: PreferOfficialVideo, expectID: "video"},
+		{name: "Prefer any", prefer: PreferAny, expectID: "topic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, Options{Match: MatchOptions{Prefer: tt.prefer}})
+
+			match := service.findBestMatch(track, videos)
+			if match == nil {
+				t.Fatalf("Expected match but got nil")
+			}
+			if match.ID != tt.expectID {
+				t.Errorf("Expected video %s, got %s", tt.expectID, match.ID)
+			}
+		})
+	}
+}
+
+func TestParsePreference(t *testing.T) {
+	if p, err := ParsePreference("Topic"); err != nil || p != PreferTopic {
+		t.Errorf("ParsePreference(Topic) = %v, %v", p, err)
+	}
+	if p, err := ParsePreference(""); err != nil || p != PreferAny {
+		t.Errorf("ParsePreference(\"\") = %v, %v", p, err)
+	}
+	if _, err := ParsePreference("lyrics"); err == nil {
+		t.Error("Expected error for unknown preference")
+	}
+}
+
+func TestFindBestMatchChannelLists(t *testing.T) {
+	track := spotify.Track{
+		Name:   "Shape of You",
+		Artist: "Ed Sheeran",
+	}
+	videos := []youtube.YouTubeVideo{
+		{
+			ID:          "reupload",
+			Title:       "Ed Sheeran - Shape of You",
+			ChannelID:   "UCreupload",
+			ChannelName: "Best Music Uploads",
+		},
+		{
+			ID:          "cover",
+			Title:       "Shape of You - Ed Sheeran (Cover)",
+			ChannelID:   "UCcover",
+			ChannelName: "Trusted Covers",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     MatchOptions
+		expectID string
+	}{
+		{name: "No lists", opts: MatchOptions{}, expectID: "reupload"},
+		{name: "Deny by ID", opts: MatchOptions{DenyChannels: []string{"UCreupload"}}, expectID: "cover"},
+		{name: "Deny by pattern", opts: MatchOptions{DenyChannels: []string{"*uploads"}}, expectID: "cover"},
+		{name: "Allow by name", opts: MatchOptions{AllowChannels: []string{"trusted covers"}}, expectID: "cover"},
+		{name: "Deny everything", opts: MatchOptions{DenyChannels: []string{"*"}}, expectID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, Options{Match: tt.opts})
+
+			match := service.findBestMatch(track, videos)
+			if tt.expectID == "" {
+				if match != nil {
+					t.Errorf("Expected no match but got %s", match.ID)
+				}
+				return
+			}
+			if match == nil || match.ID != tt.expectID {
+				t.Errorf("Expected video %s, got %v", tt.expectID, match)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"spotomusic/internal/youtube"
+)
+
+// planVersion is bumped whenever the plan file format changes
+const planVersion = 1
+
+// YouTube Data API quota costs, see
+// https://developers.google.com/youtube/v3/determine_quota_cost
+const (
+	quotaSearch = 100
+	quotaInsert = 50
+	quotaList   = 1
+)
+
+// Plan is the outcome of a dry run: everything needed to apply the transfer
+// later without searching again
+type Plan struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Options   Options           `json:"options"`
+	Playlists []PlannedPlaylist `json:"playlists"`
+}
+
+// PlannedPlaylist describes the changes planned for one YouTube playlist
+type PlannedPlaylist struct {
+	SourcePlaylistID string `json:"source_playlist_id"`
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	// PlaylistID is the existing destination playlist; empty means the
+	// playlist will be created
+	PlaylistID string `json:"playlist_id,omitempty"`
+	// Privacy of a created playlist; empty means private
+	Privacy string        `json:"privacy,omitempty"`
+	Tracks  []TrackResult `json:"tracks"`
+}
+
+// NewPlan turns the results of a dry run into a plan
+func NewPlan(options Options, results []TransferResult) Plan {
+	plan := Plan{
+		Version:   planVersion,
+		CreatedAt: time.Now().UTC(),
+		Options:   options,
+	}
+
+	for _, result := range results {
+		planned := PlannedPlaylist{
+			SourcePlaylistID: result.SourcePlaylistID,
+			Title:            result.PlaylistName,
+			Tracks:           result.Tracks,
+		}
+		if result.YouTubePlaylist != nil {
+			planned.Title = result.YouTubePlaylist.Title
+			planned.Description = result.YouTubePlaylist.Description
+			planned.PlaylistID = result.YouTubePlaylist.ID
+			planned.Privacy = result.YouTubePlaylist.Privacy
+		}
+		plan.Playlists = append(plan.Playlists, planned)
+	}
+
+	return plan
+}
+
+// Counts returns how many playlists will be created and how many tracks
+// will be added or stay unmatched
+func (p Plan) Counts() (creates, adds, unmatched int) {
+	for _, playlist := range p.Playlists {
+		if playlist.PlaylistID == "" {
+			creates++
+		}
+		for _, track := range playlist.Tracks {
+			if track.Failed() {
+				unmatched++
+			} else {
+				adds++
+			}
+		}
+	}
+	return creates, adds, unmatched
+}
+
+// EstimatedQuota returns the YouTube API units needed to apply the plan
+func (p Plan) EstimatedQuota() int {
+	creates, adds, _ := p.Counts()
+	// Each created playlist is re-checked for existence before creating it
+	return creates*(quotaInsert+quotaList) + adds*quotaInsert
+}
+
+// SearchQuota returns the YouTube API units spent on searches for the plan
+func (p Plan) SearchQuota() int {
+	searches := 0
+	for _, playlist := range p.Playlists {
+		searches += len(playlist.Tracks)
+	}
+	return searches * quotaSearch
+}
+
+// Print writes a diff-style summary of the plan
+func (p Plan) Print(w io.Writer) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	for _, playlist := range p.Playlists {
+		if playlist.PlaylistID == "" {
+			fmt.Fprintf(w, "%s playlist %q (create)\n", green("+"), playlist.Title)
+		} else {
+			fmt.Fprintf(w, "%s playlist %q (existing %s)\n", yellow("~"), playlist.Title, playlist.PlaylistID)
+		}
+
+		for _, track := range playlist.Tracks {
+			name := fmt.Sprintf("%s - %s", track.Track.Artist, track.Track.Name)
+			if track.Failed() {
+				fmt.Fprintf(w, "    %s %s (%s)\n", red("!"), name, track.Status)
+				continue
+			}
+			fmt.Fprintf(w, "    %s %s -> %s [%s, score %d]\n", green("+"), name, track.Video.Title, track.Video.ChannelName, track.Score)
+		}
+	}
+
+	creates, adds, unmatched := p.Counts()
+	fmt.Fprintf(w, "\nPlan: %d playlists to create, %d tracks to add, %d tracks unmatched\n", creates, adds, unmatched)
+	fmt.Fprintf(w, "Estimated quota to apply: %d units (searching used %d units)\n", p.EstimatedQuota(), p.SearchQuota())
+}
+
+// WriteFile saves the plan as JSON
+func (p Plan) WriteFile(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("plan dosyası yazılamadı: %v", err)
+	}
+	return nil
+}
+
+// LoadPlan reads a plan saved with WriteFile
+func LoadPlan(path string) (Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Plan{}, fmt.Errorf("plan dosyası okunamadı: %v", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return Plan{}, fmt.Errorf("plan parse edilemedi: %v", err)
+	}
+	if plan.Version != planVersion {
+		return Plan{}, fmt.Errorf("unsupported plan version %d (expected %d)", plan.Version, planVersion)
+	}
+	return plan, nil
+}
+
+// ApplyPlan executes a saved plan: it creates the planned playlists and
+// inserts the matched videos without searching again
+func (s *Service) ApplyPlan(ctx context.Context, plan Plan) ([]TransferResult, error) {
+	if err := s.initializeClients(ctx, false); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	insertCtx := context.WithoutCancel(ctx)
+
+	var results []TransferResult
+	for _, planned := range plan.Playlists {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("\nApplying: %s\n", planned.Title)
+
+		youtubePlaylist := &youtube.YouTubePlaylist{
+			ID:          planned.PlaylistID,
+			Title:       planned.Title,
+			Description: planned.Description,
+		}
+		created := false
+		if planned.PlaylistID == "" {
+			var err error
+			youtubePlaylist, created, err = s.resolvePlaylist(ctx, planned.Title, planned.Description, planned.Privacy, false)
+			if errors.Is(err, errPlaylistExists) {
+				continue
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+		}
+
+		result := TransferResult{
+			PlaylistName:     youtubePlaylist.Title,
+			SourcePlaylistID: planned.SourcePlaylistID,
+			TotalTracks:      len(planned.Tracks),
+			YouTubePlaylist:  youtubePlaylist,
+			CreatedPlaylist:  created,
+			Preference:       plan.Options.Match.Prefer,
+		}
+		for i, track := range planned.Tracks {
+			if ctx.Err() != nil {
+				result.Interrupted = true
+				for _, pending := range planned.Tracks[i:] {
+					result.Pending = append(result.Pending, pending.Track)
+				}
+				break
+			}
+			s.insertTrack(insertCtx, youtubePlaylist.ID, &track)
+			result.record(track)
+		}
+		results = append(results, result)
+	}
+
+	s.printSummary(results)
+
+	return results, ctx.Err()
+}
@@ -0,0 +1,47 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(50, 2)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() returned %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Two requests fit in the burst, the remaining four need ~80ms at 50/s
+	if elapsed < 60*time.Millisecond {
+		t.Errorf("Expected limiter to delay requests, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	limiter := newRateLimiter(0, 4)
+	if limiter != nil {
+		t.Fatalf("Expected nil limiter for zero rate")
+	}
+
+	// A nil limiter never blocks
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() returned %v", err)
+	}
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	limiter := newRateLimiter(0.1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
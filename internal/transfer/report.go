@@ -0,0 +1,206 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is the machine-readable record of a transfer run
+type Report struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Playlists   []TransferResult `json:"playlists"`
+}
+
+// NewReport builds a report from transfer results
+func NewReport(results []TransferResult) Report {
+	return Report{
+		GeneratedAt: time.Now().UTC(),
+		Playlists:   results,
+	}
+}
+
+// reportColumns are the per-track columns shared by CSV and Markdown reports
+var reportColumns = []string{"playlist", "position", "artist", "track", "album", "duration_ms", "query", "video_id", "video_title", "channel", "url", "score", "status", "error", "spotify_uri"}
+
+// ValidateReportPath checks that a report path has a supported extension
+func ValidateReportPath(path string) error {
+	_, err := Report{}.writerFor(path)
+	return err
+}
+
+// writerFor picks the report format from the file extension
+func (r Report) writerFor(path string) (func(io.Writer) error, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return r.WriteJSON, nil
+	case ".csv":
+		return r.WriteCSV, nil
+	case ".md", ".markdown":
+		return r.WriteMarkdown, nil
+	case ".html", ".htm":
+		return r.WriteHTML, nil
+	}
+	return nil, fmt.Errorf("unsupported report format %q (use .json, .csv, .md or .html)", filepath.Ext(path))
+}
+
+// WriteFile writes the report to path, picking the format from the file
+// extension: .json, .csv, .md or .html
+func (r Report) WriteFile(path string) error {
+	write, err := r.writerFor(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("report dosyası oluşturulamadı: %v", err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return fmt.Errorf("report yazılamadı: %v", err)
+	}
+	return file.Close()
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one row per track
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportColumns); err != nil {
+		return err
+	}
+	for _, playlist := range r.Playlists {
+		for _, track := range playlist.Tracks {
+			if err := writer.Write(reportRow(playlist, track)); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMarkdown writes a summary and a track table per playlist
+func (r Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Transfer Report\n\nGenerated: %s\n\n", r.GeneratedAt.Format(time.RFC3339))
+	for _, playlist := range r.Playlists {
+		fmt.Fprintf(&b, "## %s\n\n", markdownEscape(playlist.PlaylistName))
+		fmt.Fprintf(&b, "- Source playlist: %s\n", playlist.SourcePlaylistID)
+		if playlist.YouTubePlaylist != nil && playlist.YouTubePlaylist.ID != "" {
+			fmt.Fprintf(&b, "- YouTube playlist: %s\n", playlist.YouTubePlaylist.ID)
+		}
+		fmt.Fprintf(&b, "- Match preference: %s\n", playlist.Preference)
+		fmt.Fprintf(&b, "- Matched: %d / %d, failed: %d\n", playlist.MatchedTracks, playlist.TotalTracks, playlist.FailedTracks)
+		if playlist.Interrupted {
+			b.WriteString("- Interrupted before all tracks were processed\n")
+		}
+		b.WriteString("\n")
+
+		// Skip the playlist column, the heading already names it
+		columns := reportColumns[1:]
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(columns, " | "))
+		fmt.Fprintf(&b, "|%s\n", strings.Repeat(" --- |", len(columns)))
+		for _, track := range playlist.Tracks {
+			row := reportRow(playlist, track)[1:]
+			for i := range row {
+				row[i] = markdownEscape(row[i])
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(row, " | "))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML writes a standalone HTML page
+func (r Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// reportRow flattens a track result into the reportColumns order
+func reportRow(playlist TransferResult, track TrackResult) []string {
+	var videoID, videoTitle, channel, url, spotifyURI string
+	if track.Video != nil {
+		videoID = track.Video.ID
+		videoTitle = track.Video.Title
+		channel = track.Video.ChannelName
+		url = track.Video.URL
+	}
+	if track.SpotifyTrack != nil {
+		spotifyURI = track.SpotifyTrack.URI
+	}
+	return []string{
+		playlist.PlaylistName,
+		strconv.Itoa(track.Position),
+		track.Track.Artist,
+		track.Track.Name,
+		track.Track.Album,
+		strconv.Itoa(track.Track.Duration),
+		track.Query,
+		videoID,
+		videoTitle,
+		channel,
+		url,
+		strconv.Itoa(track.Score),
+		string(track.Status),
+		track.Error,
+		spotifyURI,
+	}
+}
+
+// markdownEscape keeps table cells from breaking the Markdown table
+func markdownEscape(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Transfer Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+tr.failed { background: #fdecea; }
+</style>
+</head>
+<body>
+<h1>Transfer Report</h1>
+<p>Generated: {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+{{range .Playlists}}
+<h2>{{.PlaylistName}}</h2>
+<p>Source playlist: {{.SourcePlaylistID}}{{with .YouTubePlaylist}}{{if .ID}} &middot; YouTube playlist: {{.ID}}{{end}}{{end}}<br>
+Match preference: {{.Preference}} &middot; Matched {{.MatchedTracks}} / {{.TotalTracks}}, failed {{.FailedTracks}}{{if .Interrupted}} &middot; interrupted{{end}}</p>
+<table>
+<tr><th>#</th><th>Artist</th><th>Track</th><th>Query</th><th>Video</th><th>Channel</th><th>Spotify</th><th>Score</th><th>Status</th><th>Error</th></tr>
+{{range .Tracks}}<tr{{if .Failed}} class="failed"{{end}}>
+<td>{{.Position}}</td><td>{{.Track.Artist}}</td><td>{{.Track.Name}}</td><td>{{.Query}}</td>
+<td>{{with .Video}}<a href="{{.URL}}">{{.Title}}</a>{{end}}</td><td>{{with .Video}}{{.ChannelName}}{{end}}</td><td>{{with .SpotifyTrack}}{{.Artist}} - {{.Name}}{{end}}</td>
+<td>{{.Score}}</td><td>{{.Status}}</td><td>{{.Error}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
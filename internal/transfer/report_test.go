@@ -0,0 +1,149 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+func testReport() Report {
+	return NewReport([]TransferResult{
+		{
+			PlaylistName:     "Road | Trip",
+			SourcePlaylistID: "37i9dQZF1DXcBWIGoYBM5M",
+			TotalTracks:      2,
+			MatchedTracks:    1,
+			FailedTracks:     1,
+			Preference:       PreferTopic,
+			Tracks: []TrackResult{
+				{
+					Position: 1,
+					Track:    spotify.Track{Name: "Shape of You", Artist: "Ed Sheeran"},
+					Query:    "Ed Sheeran Shape of You",
+					Video: &youtube.YouTubeVideo{
+						ID:          "JGwWNGJdvx8",
+						Title:       "Shape of You",
+						ChannelName: "Ed Sheeran - Topic",
+						URL:         "https://www.youtube.com/watch?v=JGwWNGJdvx8",
+					},
+					Score:  110,
+					Status: StatusMatched,
+				},
+				{
+					Position: 2,
+					Track:    spotify.Track{Name: "<Unknown>", Artist: "Nobody"},
+					Query:    "Nobody <Unknown>",
+					Status:   StatusNotFound,
+					Error:    "No matching video found",
+				},
+			},
+		},
+	})
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if len(decoded.Playlists) != 1 || len(decoded.Playlists[0].Tracks) != 2 {
+		t.Fatalf("Unexpected decoded report: %+v", decoded)
+	}
+	if decoded.Playlists[0].Tracks[1].Status != StatusNotFound {
+		t.Errorf("Expected status %s, got %s", StatusNotFound, decoded.Playlists[0].Tracks[1].Status)
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Report is not valid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d", len(rows))
+	}
+	if rows[1][7] != "JGwWNGJdvx8" || rows[2][12] != "not_found" {
+		t.Errorf("Unexpected CSV rows: %v", rows[1:])
+	}
+}
+
+func TestReportMarkdownAndHTML(t *testing.T) {
+	var md bytes.Buffer
+	if err := testReport().WriteMarkdown(&md); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	if !strings.Contains(md.String(), `## Road \| Trip`) {
+		t.Errorf("Expected escaped playlist heading, got:\n%s", md.String())
+	}
+
+	var page bytes.Buffer
+	if err := testReport().WriteHTML(&page); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	if strings.Contains(page.String(), "<Unknown>") {
+		t.Error("Expected track names to be HTML escaped")
+	}
+}
+
+func TestValidateReportPath(t *testing.T) {
+	for _, path := range []string{"out.json", "out.CSV", "out.md", "out.html"} {
+		if err := ValidateReportPath(path); err != nil {
+			t.Errorf("ValidateReportPath(%s) error = %v", path, err)
+		}
+	}
+	if err := ValidateReportPath("out.xlsx"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestPlanCountsAndRoundTrip(t *testing.T) {
+	results := testReport().Playlists
+	results = append(results, TransferResult{
+		PlaylistName:     "Existing",
+		SourcePlaylistID: "abc",
+		YouTubePlaylist:  &youtube.YouTubePlaylist{ID: "PL123", Title: "Existing"},
+		Tracks:           results[0].Tracks[:1],
+	})
+
+	plan := NewPlan(Options{Concurrency: 2}, results)
+	creates, adds, unmatched := plan.Counts()
+	if creates != 1 || adds != 2 || unmatched != 1 {
+		t.Errorf("Counts() = %d, %d, %d; want 1, 2, 1", creates, adds, unmatched)
+	}
+	if want := (quotaInsert + quotaList) + 2*quotaInsert; plan.EstimatedQuota() != want {
+		t.Errorf("EstimatedQuota() = %d, want %d", plan.EstimatedQuota(), want)
+	}
+
+	var buf bytes.Buffer
+	plan.Print(&buf)
+	if !strings.Contains(buf.String(), `playlist "Existing" (existing PL123)`) {
+		t.Errorf("Plan output misses the existing playlist:\n%s", buf.String())
+	}
+
+	path := t.TempDir() + "/plan.json"
+	if err := plan.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	loaded, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("LoadPlan() error = %v", err)
+	}
+	if len(loaded.Playlists) != 2 || loaded.Playlists[1].PlaylistID != "PL123" || loaded.Options.Concurrency != 2 {
+		t.Errorf("Unexpected loaded plan: %+v", loaded)
+	}
+}
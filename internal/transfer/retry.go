@@ -0,0 +1,174 @@
+package transfer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"spotomusic/internal/profile"
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// RetryList holds the tracks that failed during a run so they can be
+// transferred again later
+type RetryList struct {
+	RunID     string          `json:"run_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Playlists []RetryPlaylist `json:"playlists"`
+}
+
+// RetryPlaylist is the set of failed tracks for one destination playlist
+type RetryPlaylist struct {
+	SourcePlaylistID  string          `json:"source_playlist_id"`
+	PlaylistName      string          `json:"playlist_name"`
+	YouTubePlaylistID string          `json:"youtube_playlist_id"`
+	Tracks            []spotify.Track `json:"tracks"`
+}
+
+// NewRunID returns an identifier for a new transfer run. The random suffix
+// keeps runs started in the same second apart.
+func NewRunID() string {
+	now := time.Now()
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// Without randomness the nanoseconds still tell runs apart
+		return fmt.Sprintf("%s-%08x", now.Format("20060102-150405"), now.Nanosecond())
+	}
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// NewRetryList collects the failed tracks of a run and the tracks an
+// interrupted run never got to. Playlists without either are left out.
+func NewRetryList(runID string, results []TransferResult) RetryList {
+	list := RetryList{
+		RunID:     runID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	for _, result := range results {
+		failures := result.Failures()
+		// Retries search YouTube, so reverse transfers are not retryable
+		if len(failures)+len(result.Pending) == 0 || result.Direction == DirectionToSpotify {
+			continue
+		}
+
+		playlist := RetryPlaylist{
+			SourcePlaylistID: result.SourcePlaylistID,
+			PlaylistName:     result.PlaylistName,
+		}
+		if result.YouTubePlaylist != nil {
+			playlist.YouTubePlaylistID = result.YouTubePlaylist.ID
+		}
+		for _, failure := range failures {
+			playlist.Tracks = append(playlist.Tracks, failure.Track)
+		}
+		playlist.Tracks = append(playlist.Tracks, result.Pending...)
+		list.Playlists = append(list.Playlists, playlist)
+	}
+
+	return list
+}
+
+// TrackCount returns the number of tracks in the list
+func (l RetryList) TrackCount() int {
+	count := 0
+	for _, playlist := range l.Playlists {
+		count += len(playlist.Tracks)
+	}
+	return count
+}
+
+// RetryListPath returns where the retry list of a run of the active
+// profile is stored
+func RetryListPath(runID string) (string, error) {
+	dir, err := profile.Active().DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "retry", runID+".json"), nil
+}
+
+// Save writes the retry list to its default location and returns the path
+func (l RetryList) Save() (string, error) {
+	path, err := RetryListPath(l.RunID)
+	if err != nil {
+		return "", err
+	}
+	return path, l.WriteFile(path)
+}
+
+// WriteFile writes the retry list as JSON to path
+func (l RetryList) WriteFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("retry directory oluşturulamadı: %v", err)
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadRetryList loads a retry list by run ID, or from a file path
+func LoadRetryList(runIDOrPath string) (RetryList, error) {
+	path := runIDOrPath
+	if _, err := os.Stat(path); err != nil {
+		path, err = RetryListPath(runIDOrPath)
+		if err != nil {
+			return RetryList{}, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RetryList{}, fmt.Errorf("retry list bulunamadı (%s): %v", runIDOrPath, err)
+	}
+
+	var list RetryList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return RetryList{}, fmt.Errorf("retry list parse edilemedi: %v", err)
+	}
+	return list, nil
+}
+
+// RetryFailed transfers the tracks of a retry list into the YouTube
+// playlists they were originally meant for
+func (s *Service) RetryFailed(ctx context.Context, list RetryList, dryRun bool) ([]TransferResult, error) {
+	if err := s.initializeClients(ctx, dryRun); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	fmt.Printf("Retrying %d failed tracks from run %s\n", list.TrackCount(), list.RunID)
+
+	var results []TransferResult
+	for _, playlist := range list.Playlists {
+		if ctx.Err() != nil {
+			break
+		}
+		if playlist.YouTubePlaylistID == "" {
+			fmt.Printf("Skipping %s: no YouTube playlist was created in run %s\n", playlist.PlaylistName, list.RunID)
+			continue
+		}
+
+		fmt.Printf("\nRetrying: %s\n", playlist.PlaylistName)
+		youtubePlaylist := &youtube.YouTubePlaylist{
+			ID:    playlist.YouTubePlaylistID,
+			Title: playlist.PlaylistName,
+		}
+
+		result := s.transferTracks(ctx, playlist.Tracks, youtubePlaylist, dryRun)
+		result.SourcePlaylistID = playlist.SourcePlaylistID
+		results = append(results, result)
+	}
+
+	s.printSummary(results)
+
+	return results, ctx.Err()
+}
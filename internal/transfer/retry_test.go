@@ -0,0 +1,63 @@
+package transfer
+
+import (
+	"testing"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+func TestRetryListRoundTrip(t *testing.T) {
+	results := testReport().Playlists
+	results[0].YouTubePlaylist = &youtube.YouTubePlaylist{ID: "PL123", Title: "Road | Trip"}
+
+	list := NewRetryList("20240101-120000", results)
+	if list.TrackCount() != 1 {
+		t.Fatalf("Expected 1 failed track, got %d", list.TrackCount())
+	}
+
+	path := t.TempDir() + "/failed.json"
+	if err := list.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	loaded, err := LoadRetryList(path)
+	if err != nil {
+		t.Fatalf("LoadRetryList() error = %v", err)
+	}
+	if loaded.RunID != list.RunID || loaded.Playlists[0].YouTubePlaylistID != "PL123" {
+		t.Errorf("Unexpected retry list: %+v", loaded)
+	}
+	if loaded.Playlists[0].Tracks[0].Name != "<Unknown>" {
+		t.Errorf("Expected failed track to be kept, got %+v", loaded.Playlists[0].Tracks)
+	}
+}
+
+func TestRetryListKeepsPendingTracks(t *testing.T) {
+	tracks := []spotify.Track{{Name: "One"}, {Name: "Two"}, {Name: "Three"}}
+	result := TransferResult{
+		TotalTracks:     len(tracks),
+		YouTubePlaylist: &youtube.YouTubePlaylist{ID: "PL123"},
+		Tracks:          []TrackResult{{Position: 1, Track: tracks[0], Status: StatusMatched}},
+	}
+	result.markInterrupted(tracks)
+	if !result.Interrupted || len(result.Pending) != 2 {
+		t.Fatalf("markInterrupted() = %v, %+v", result.Interrupted, result.Pending)
+	}
+
+	list := NewRetryList("20240101-120000", []TransferResult{result})
+	if list.TrackCount() != 2 || list.Playlists[0].Tracks[0].Name != "Two" {
+		t.Errorf("Unexpected retry list: %+v", list)
+	}
+}
+
+func TestNewRunIDIsUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		id := NewRunID()
+		if seen[id] {
+			t.Fatalf("NewRunID() repeated %s", id)
+		}
+		seen[id] = true
+	}
+}
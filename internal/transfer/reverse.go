@@ -0,0 +1,331 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// Direction tells which service a transfer reads from and writes to
+type Direction string
+
+const (
+	// DirectionToYouTube copies Spotify playlists to YouTube (the default)
+	DirectionToYouTube Direction = ""
+	// DirectionToSpotify copies YouTube playlists to Spotify
+	DirectionToSpotify Direction = "youtube_to_spotify"
+)
+
+var (
+	// titleNoise matches bracketed parts of video titles that describe the
+	// upload rather than the recording, e.g. "(Official Video)" or "[HD]"
+	titleNoise = regexp.MustCompile(`(?i)\s*[\(\[][^\)\]]*\b(official|video|audio|lyrics?|visuali[sz]er|hd|hq|4k|mv|m/v)\b[^\)\]]*[\)\]]`)
+	// featuring matches a trailing featured artist credit
+	featuring = regexp.MustCompile(`(?i)\s*[\(\[]?\s*\b(ft\.?|feat\.?|featuring)\s.*$`)
+)
+
+// titleSeparators split "Artist - Title" style video titles
+var titleSeparators = []string{" - ", " – ", " — ", " | "}
+
+// ParseVideoTitle extracts the artist and title of the recording behind a
+// YouTube video. Topic channels carry the artist in the channel name; other
+// uploads usually follow the "Artist - Title" convention, with the channel
+// as a fallback artist.
+func ParseVideoTitle(video youtube.YouTubeVideo) spotify.Track {
+	title := strings.TrimSpace(titleNoise.ReplaceAllString(video.Title, ""))
+	channel := strings.TrimSpace(video.ChannelName)
+
+	var artist string
+	if isTopicChannel(video) {
+		artist = strings.TrimSpace(channel[:len(channel)-len(" - Topic")])
+	} else {
+		for _, sep := range titleSeparators {
+			if idx := strings.Index(title, sep); idx > 0 {
+				artist = strings.TrimSpace(title[:idx])
+				title = strings.TrimSpace(title[idx+len(sep):])
+				break
+			}
+		}
+		if artist == "" {
+			artist = channel
+			for _, suffix := range []string{"VEVO", "Official"} {
+				if len(artist) > len(suffix) && strings.EqualFold(artist[len(artist)-len(suffix):], suffix) {
+					artist = strings.TrimSpace(artist[:len(artist)-len(suffix)])
+				}
+			}
+		}
+	}
+
+	title = strings.TrimSpace(featuring.ReplaceAllString(title, ""))
+	title = strings.Trim(title, `"'“”`)
+
+	return spotify.Track{
+		Name:   title,
+		Artist: artist,
+	}
+}
+
+// scoreSpotifyTrack rates how well a Spotify track matches a track parsed
+// from a video. It shares the title and artist rules of scoreVideo; channel
+// preferences do not apply to Spotify.
+func scoreSpotifyTrack(parsed, candidate spotify.Track) int {
+	score := scoreTitles(parsed.Name, parsed.Artist, candidate.Name, candidate.Artist)
+	if score == 0 {
+		return 0
+	}
+	return score - unwantedPenalty(parsed.Name, candidate.Name)
+}
+
+// bestSpotifyMatch returns the highest scoring Spotify track and its score,
+// or nil when no candidate reaches minMatchScore
+func bestSpotifyMatch(parsed spotify.Track, candidates []spotify.Track) (*spotify.Track, int) {
+	var best *spotify.Track
+	bestScore := 0
+
+	for i := range candidates {
+		score := scoreSpotifyTrack(parsed, candidates[i])
+		if score > bestScore {
+			best = &candidates[i]
+			bestScore = score
+		}
+	}
+
+	if bestScore < minMatchScore {
+		return nil, bestScore
+	}
+	return best, bestScore
+}
+
+// youtubePlaylistID accepts a playlist ID or a playlist URL
+func youtubePlaylistID(value string) string {
+	if parsed, err := url.Parse(value); err == nil && parsed.Host != "" {
+		if list := parsed.Query().Get("list"); list != "" {
+			return list
+		}
+	}
+	return value
+}
+
+// TransferToSpotify copies a YouTube playlist into a Spotify playlist owned
+// by the logged-in Spotify user. playlistName overrides the YouTube title.
+func (s *Service) TransferToSpotify(ctx context.Context, youtubePlaylistRef, playlistName string, dryRun bool) ([]TransferResult, error) {
+	if err := s.initializeReverseClients(ctx); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	source, err := s.youtubeClient.GetPlaylist(ctx, youtubePlaylistID(youtubePlaylistRef))
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.youtubeClient.GetPlaylistItems(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	if playlistName == "" {
+		playlistName = source.Title
+	}
+
+	fmt.Printf("Transferring YouTube playlist: %s (%d videos)\n", source.Title, len(items))
+
+	destination, created, err := s.resolveSpotifyPlaylist(ctx, playlistName, fmt.Sprintf("Transferred from YouTube playlist: %s", source.ID), dryRun)
+	if errors.Is(err, errPlaylistExists) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.transferItems(ctx, items, destination, dryRun)
+	result.SourcePlaylistID = source.ID
+	result.YouTubePlaylist = source
+	result.CreatedPlaylist = created
+	s.printTransferResult(result)
+
+	return []TransferResult{result}, ctx.Err()
+}
+
+// initializeReverseClients initializes the clients for YouTube to Spotify
+// transfers. Spotify search needs a login even in dry-run mode.
+func (s *Service) initializeReverseClients(ctx context.Context) error {
+	var err error
+
+	if s.youtubeClient == nil {
+		client, err := youtube.NewClient(ctx, youtube.Options{
+			ReadOnly: true,
+			APIKey:   s.options.YouTubeAPIKey,
+		})
+		if err != nil {
+			return fmt.Errorf("YouTube client: %v", err)
+		}
+		s.youtubeClient = client
+	}
+
+	if s.spotifyClient == nil || !s.spotifyClient.Authenticated() {
+		s.spotifyClient, err = spotify.NewUserClient(ctx)
+		if err != nil {
+			return fmt.Errorf("Spotify client: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// resolveSpotifyPlaylist finds or creates the destination Spotify playlist.
+// In dry-run mode nothing is created and the returned playlist has no ID.
+// With skip_existing an existing playlist yields errPlaylistExists.
+func (s *Service) resolveSpotifyPlaylist(ctx context.Context, name, description string, dryRun bool) (*spotify.Playlist, bool, error) {
+	exists, existingPlaylist, err := s.spotifyClient.PlaylistExists(ctx, name)
+	if err != nil {
+		return nil, false, fmt.Errorf("playlist existence check failed: %v", err)
+	}
+	if exists && s.skipExisting {
+		fmt.Printf("Playlist '%s' already exists on Spotify. Skipping it (use --skip-existing=false to add to it).\n", name)
+		return nil, false, errPlaylistExists
+	}
+	if exists {
+		fmt.Printf("Playlist '%s' already exists on Spotify. Using existing playlist.\n", name)
+		return existingPlaylist, false, nil
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would create Spotify playlist: %s\n", name)
+		return &spotify.Playlist{Name: name, Description: description}, false, nil
+	}
+
+	playlist, err := s.spotifyClient.CreatePlaylist(ctx, name, description)
+	if err != nil {
+		return nil, false, fmt.Errorf("Spotify playlist oluşturulamadı: %v", err)
+	}
+	fmt.Printf("Created Spotify playlist: %s\n", playlist.Name)
+	return playlist, true, nil
+}
+
+// transferItems matches YouTube playlist items on Spotify and adds the
+// matches to the destination playlist in batches, keeping the YouTube order
+func (s *Service) transferItems(ctx context.Context, items []youtube.PlaylistItem, destination *spotify.Playlist, dryRun bool) TransferResult {
+	result := TransferResult{
+		PlaylistName:    destination.Name,
+		TotalTracks:     len(items),
+		Direction:       DirectionToSpotify,
+		SpotifyPlaylist: destination,
+		Preference:      s.match.Prefer,
+	}
+
+	fmt.Printf("Transferring %d videos (workers: %d)...\n", len(items), s.concurrency)
+
+	insertCtx := context.WithoutCancel(ctx)
+
+	// Matches are held back until a full batch can be added, so the printed
+	// outcome already includes add errors
+	var pending []TrackResult
+	flush := func() {
+		if !dryRun {
+			s.addSpotifyTracks(insertCtx, destination.ID, pending)
+		}
+		for _, match := range pending {
+			result.record(match)
+		}
+		pending = pending[:0]
+	}
+
+	match := func(ctx context.Context, i int) TrackResult {
+		return s.matchItem(ctx, items[i])
+	}
+	s.matchTracks(ctx, len(items), match, func(match TrackResult) {
+		pending = append(pending, match)
+		if len(pending) == spotifyBatchSize {
+			flush()
+		}
+	})
+	flush()
+
+	if ctx.Err() != nil && len(result.Tracks) < result.TotalTracks {
+		result.Interrupted = true
+	}
+
+	return result
+}
+
+// spotifyBatchSize is how many tracks are added to Spotify per request
+const spotifyBatchSize = 100
+
+// addSpotifyTracks adds the matched tracks of a batch, turning a failed
+// request into StatusAddError for every track in it
+func (s *Service) addSpotifyTracks(ctx context.Context, playlistID string, batch []TrackResult) {
+	var uris []string
+	for _, match := range batch {
+		if match.Status == StatusMatched {
+			uris = append(uris, match.SpotifyTrack.URI)
+		}
+	}
+	if len(uris) == 0 {
+		return
+	}
+
+	err := s.limiter.Wait(ctx)
+	if err == nil {
+		err = s.spotifyClient.AddTracksToPlaylist(ctx, playlistID, uris)
+	}
+	if err == nil {
+		return
+	}
+	for i := range batch {
+		if batch[i].Status == StatusMatched {
+			batch[i].Status = StatusAddError
+			batch[i].Error = err.Error()
+		}
+	}
+}
+
+// matchItem searches Spotify for the recording behind a YouTube video
+func (s *Service) matchItem(ctx context.Context, item youtube.PlaylistItem) TrackResult {
+	video := item.Video
+	parsed := ParseVideoTitle(video)
+	result := TrackResult{
+		Track: parsed,
+		Video: &video,
+		Query: strings.Join(strings.Fields(parsed.Artist+" "+parsed.Name), " "),
+	}
+
+	if item.Unavailable() {
+		result.Status = StatusNotFound
+		result.Error = "Video is deleted or private"
+		return result
+	}
+
+	if err := s.limiter.Wait(ctx); err != nil {
+		result.Status = StatusSearchError
+		result.Error = err.Error()
+		return result
+	}
+	candidates, err := s.spotifyClient.SearchTrack(ctx, result.Query)
+	if err != nil {
+		result.Status = StatusSearchError
+		result.Error = err.Error()
+		return result
+	}
+
+	if len(candidates) == 0 {
+		result.Status = StatusNotFound
+		result.Error = "No matching track found"
+		return result
+	}
+
+	best, score := bestSpotifyMatch(parsed, candidates)
+	result.Score = score
+	if best == nil {
+		result.Status = StatusNoGoodMatch
+		result.Error = "No good match found"
+		return result
+	}
+
+	result.SpotifyTrack = best
+	result.Status = StatusMatched
+	return result
+}
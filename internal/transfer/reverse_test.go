@@ -0,0 +1,90 @@
+package transfer
+
+import (
+	"testing"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+func TestParseVideoTitle(t *testing.T) {
+	tests := []struct {
+		name   string
+		video  youtube.YouTubeVideo
+		artist string
+		title  string
+	}{
+		{
+			name:   "Topic channel",
+			video:  youtube.YouTubeVideo{Title: "Shape of You", ChannelName: "Ed Sheeran - Topic"},
+			artist: "Ed Sheeran",
+			title:  "Shape of You",
+		},
+		{
+			name:   "Artist - Title with noise",
+			video:  youtube.YouTubeVideo{Title: "Ed Sheeran - Shape of You [Official Video]", ChannelName: "Ed Sheeran"},
+			artist: "Ed Sheeran",
+			title:  "Shape of You",
+		},
+		{
+			name:   "Featured artist",
+			video:  youtube.YouTubeVideo{Title: "Ariana Grande - Side To Side ft. Nicki Minaj (Official Audio)", ChannelName: "ArianaGrandeVevo"},
+			artist: "Ariana Grande",
+			title:  "Side To Side",
+		},
+		{
+			name:   "Channel as artist",
+			video:  youtube.YouTubeVideo{Title: "Bad Guy (Lyrics)", ChannelName: "BillieEilishVEVO"},
+			artist: "BillieEilish",
+			title:  "Bad Guy",
+		},
+		{
+			name:   "Remix is kept",
+			video:  youtube.YouTubeVideo{Title: "Dua Lipa - Levitating (Remix)", ChannelName: "Dua Lipa"},
+			artist: "Dua Lipa",
+			title:  "Levitating (Remix)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseVideoTitle(tt.video)
+			if got.Artist != tt.artist || got.Name != tt.title {
+				t.Errorf("ParseVideoTitle() = %q / %q, want %q / %q", got.Artist, got.Name, tt.artist, tt.title)
+			}
+		})
+	}
+}
+
+func TestBestSpotifyMatch(t *testing.T) {
+	parsed := spotify.Track{Artist: "Ed Sheeran", Name: "Shape of You"}
+	candidates := []spotify.Track{
+		{ID: "1", Artist: "Ed Sheeran", Name: "Shape of You - Acoustic Live"},
+		{ID: "2", Artist: "Ed Sheeran", Name: "Shape of You"},
+		{ID: "3", Artist: "Someone Else", Name: "Castle on the Hill"},
+	}
+
+	best, score := bestSpotifyMatch(parsed, candidates)
+	if best == nil || best.ID != "2" {
+		t.Fatalf("bestSpotifyMatch() = %+v, want track 2", best)
+	}
+	if score != scoreTitleMatch+scoreArtistMatch {
+		t.Errorf("score = %d, want %d", score, scoreTitleMatch+scoreArtistMatch)
+	}
+
+	if best, _ := bestSpotifyMatch(parsed, candidates[2:]); best != nil {
+		t.Errorf("Expected no match, got %+v", best)
+	}
+}
+
+func TestYouTubePlaylistID(t *testing.T) {
+	for input, want := range map[string]string{
+		"PLabc123": "PLabc123",
+		"https://www.youtube.com/playlist?list=PLabc123":          "PLabc123",
+		"https://music.youtube.com/playlist?list=PLabc123&si=xyz": "PLabc123",
+	} {
+		if got := youtubePlaylistID(input); got != want {
+			t.Errorf("youtubePlaylistID(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -1,122 +1,297 @@
 package transfer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
+	"spotomusic/internal/config"
 	"spotomusic/internal/spotify"
 	"spotomusic/internal/youtube"
 )
 
+// playlistSource provides the Spotify playlists to transfer. It is the
+// Spotify client itself or an offline snapshot.
+type playlistSource interface {
+	GetUserPlaylists(ctx context.Context) ([]spotify.Playlist, error)
+	GetPlaylistInfo(ctx context.Context, playlistID string, playlistName string) (spotify.Playlist, error)
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]spotify.Track, error)
+}
+
+// youtubeAPI is the part of the YouTube client the service uses
+type youtubeAPI interface {
+	Authenticated() bool
+	CreatePlaylist(ctx context.Context, title, description, privacy string) (*youtube.YouTubePlaylist, error)
+	UpdatePlaylist(ctx context.Context, playlistID, title, description string) (*youtube.YouTubePlaylist, error)
+	DeletePlaylist(ctx context.Context, playlistID string) error
+	GetPlaylist(ctx context.Context, playlistID string) (*youtube.YouTubePlaylist, error)
+	PlaylistExists(ctx context.Context, title string) (bool, *youtube.YouTubePlaylist, error)
+	GetPlaylistItems(ctx context.Context, playlistID string) ([]youtube.PlaylistItem, error)
+	AddVideoToPlaylist(ctx context.Context, playlistID, videoID string) (string, error)
+	InsertVideoAt(ctx context.Context, playlistID, videoID string, position int) (string, error)
+	DeletePlaylistItem(ctx context.Context, playlistItemID string) error
+	SearchVideo(ctx context.Context, query string, opts youtube.SearchOptions) ([]youtube.YouTubeVideo, error)
+	GetVideoDetails(ctx context.Context, videoIDs []string) (map[string]youtube.VideoDetails, error)
+}
+
 type Service struct {
 	spotifyClient *spotify.Client
-	youtubeClient *youtube.Client
+	source        playlistSource
+	youtubeClient youtubeAPI
+	options       Options
+	match         MatchOptions
+	concurrency   int
+	limiter       *rateLimiter
+	retry         retryPolicy
+	// skipExisting leaves playlists alone that already exist on the
+	// destination instead of adding to them
+	skipExisting bool
+	destination  Destination
 }
 
 type TransferResult struct {
-	PlaylistName    string
-	TotalTracks     int
-	MatchedTracks   int
-	FailedTracks    int
-	YouTubePlaylist *youtube.YouTubePlaylist
-	Errors          []string
+	PlaylistName     string                   `json:"playlist_name"`
+	SourcePlaylistID string                   `json:"source_playlist_id"`
+	TotalTracks      int                      `json:"total_tracks"`
+	MatchedTracks    int                      `json:"matched_tracks"`
+	FailedTracks     int                      `json:"failed_tracks"`
+	YouTubePlaylist  *youtube.YouTubePlaylist `json:"youtube_playlist"`
+	// Direction is empty for Spotify to YouTube transfers
+	Direction Direction `json:"direction,omitempty"`
+	// SpotifyPlaylist is the destination of YouTube to Spotify transfers
+	SpotifyPlaylist *spotify.Playlist `json:"spotify_playlist,omitempty"`
+	// CreatedPlaylist is set when the YouTube playlist was created by this run
+	CreatedPlaylist bool       `json:"created_playlist"`
+	Preference      Preference `json:"preference"`
+	// Interrupted is set when the run was cancelled before every track
+	// was processed
+	Interrupted bool          `json:"interrupted"`
+	Tracks      []TrackResult `json:"tracks"`
+	// Pending are the tracks an interrupted run never processed
+	Pending []spotify.Track `json:"pending,omitempty"`
+	// Removed are the videos a sync pruned because their track left the
+	// source playlist
+	Removed []youtube.PlaylistItem `json:"removed,omitempty"`
+	// Playlist are the templates the YouTube playlist was named and
+	// described with, so sync keeps using them
+	Playlist *PlaylistOptions `json:"playlist,omitempty"`
+}
+
+// TrackStatus is the outcome of transferring a single track
+type TrackStatus string
+
+const (
+	StatusMatched     TrackStatus = "matched"
+	StatusNotFound    TrackStatus = "not_found"
+	StatusNoGoodMatch TrackStatus = "no_good_match"
+	StatusSearchError TrackStatus = "search_error"
+	StatusAddError    TrackStatus = "add_error"
+)
+
+// TrackResult records how a single source track was transferred
+type TrackResult struct {
+	Position int                   `json:"position"`
+	Track    spotify.Track         `json:"track"`
+	Query    string                `json:"query"`
+	Video    *youtube.YouTubeVideo `json:"video,omitempty"`
+	// SpotifyTrack is the match of YouTube to Spotify transfers
+	SpotifyTrack *spotify.Track `json:"spotify_track,omitempty"`
+	// PlaylistItemID identifies the inserted item so it can be undone
+	PlaylistItemID string      `json:"playlist_item_id,omitempty"`
+	Score          int         `json:"score"`
+	Status         TrackStatus `json:"status"`
+	Error          string      `json:"error,omitempty"`
+}
+
+// Failed reports whether the track did not end up in the playlist
+func (r TrackResult) Failed() bool {
+	return r.Status != StatusMatched
+}
+
+// MatchTitle describes what the track was matched to, or "" without a match
+func (r TrackResult) MatchTitle() string {
+	switch {
+	case r.SpotifyTrack != nil:
+		return r.SpotifyTrack.Artist + " - " + r.SpotifyTrack.Name
+	case r.Video != nil:
+		return r.Video.Title
+	}
+	return ""
+}
+
+// Failures returns the tracks that did not end up in the playlist
+func (r TransferResult) Failures() []TrackResult {
+	var failures []TrackResult
+	for _, track := range r.Tracks {
+		if track.Failed() {
+			failures = append(failures, track)
+		}
+	}
+	return failures
 }
 
-// NewService creates a new transfer service
-func NewService() *Service {
-	return &Service{}
+// Options configures a transfer service
+type Options struct {
+	Match MatchOptions `json:"match"`
+	// Concurrency is the number of tracks searched in parallel
+	Concurrency int `json:"concurrency"`
+	// RequestsPerSecond caps YouTube API calls across all workers;
+	// zero disables the limit
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// YouTubeAPIKey allows dry runs without an OAuth login
+	YouTubeAPIKey string `json:"-"`
+	// Snapshot is the backup file read instead of Spotify, if any
+	Snapshot string `json:"snapshot,omitempty"`
+	// Export writes matches to a playlist file instead of YouTube, in the
+	// --to syntax (see ParseDestination). Empty means YouTube.
+	Export string `json:"export,omitempty"`
+	// Playlist holds the title and description templates and privacy of
+	// created playlists; manifest entries override them
+	Playlist PlaylistOptions `json:"playlist"`
+}
+
+// NewService creates a new transfer service. The retry policy and the
+// handling of existing playlists come from cfg; a nil cfg repeats no
+// requests and adds to existing playlists.
+func NewService(cfg *config.Config, opts Options) *Service {
+	if opts.Match.Prefer == "" {
+		opts.Match.Prefer = PreferAny
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	// Callers validate Export with ParseDestination first
+	destination, _ := ParseDestination(opts.Export)
+	s := &Service{
+		options:     opts,
+		match:       opts.Match,
+		concurrency: opts.Concurrency,
+		limiter:     newRateLimiter(opts.RequestsPerSecond, opts.Concurrency),
+		destination: destination,
+	}
+	if cfg != nil {
+		s.retry = retryPolicy{
+			maxRetries: cfg.Transfer.MaxRetries,
+			delay:      time.Duration(cfg.Transfer.RetryDelay) * time.Millisecond,
+		}
+		s.skipExisting = cfg.Transfer.SkipExisting
+	}
+	return s
+}
+
+// Options returns the options the service was created with
+func (s *Service) Options() Options {
+	return s.options
+}
+
+// UseSnapshot makes the service read playlists from a backup snapshot
+// instead of Spotify
+func (s *Service) UseSnapshot(path string) error {
+	snapshot, err := spotify.LoadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Using snapshot %s taken %s\n", path, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	s.source = snapshot
+	s.options.Snapshot = path
+	return nil
 }
 
 // TransferPlaylist transfers a single playlist from Spotify to YouTube Music
-func (s *Service) TransferPlaylist(playlistID string, playlistName string, dryRun bool) error {
+func (s *Service) TransferPlaylist(ctx context.Context, playlistID string, playlistName string, dryRun bool) ([]TransferResult, error) {
 	// Initialize clients
-	if err := s.initializeClients(); err != nil {
-		return fmt.Errorf("clients initialize edilemedi: %v", err)
+	if err := s.initializeClients(ctx, dryRun); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+	return s.transferPlaylist(ctx, playlistID, playlistName, PlaylistOptions{}, dryRun)
+}
+
+// transferPlaylist transfers a single playlist into the destination
+// described by options
+func (s *Service) transferPlaylist(ctx context.Context, playlistID string, playlistName string, options PlaylistOptions, dryRun bool) ([]TransferResult, error) {
+	spotifyPlaylist := spotify.Playlist{
+		ID:   playlistID,
+		Name: playlistName,
 	}
 
-	// If playlistName is not provided, try to get it from Spotify
-	if playlistName == "" {
-		spotifyPlaylistInfo, err := s.spotifyClient.GetPlaylistInfo(playlistID, "Unknown Playlist")
+	options = options.withDefaults(s.options.Playlist).dated()
+
+	// If playlistName is not provided, try to get it from Spotify. Templates
+	// also need the owner and description.
+	if playlistName == "" || options.templated() {
+		spotifyPlaylistInfo, err := s.source.GetPlaylistInfo(ctx, playlistID, "Unknown Playlist")
 		if err != nil {
-			return fmt.Errorf("failed to get playlist info: %v", err)
+			return nil, fmt.Errorf("failed to get playlist info: %v", err)
 		}
 		// Update playlist name from GetPlaylistInfo result
-		playlistName = spotifyPlaylistInfo.Name
+		spotifyPlaylist = spotifyPlaylistInfo
+		if playlistName != "" {
+			spotifyPlaylist.Name = playlistName
+		}
 	}
 
 	// Get tracks
-	tracks, err := s.spotifyClient.GetPlaylistTracks(playlistID)
+	tracks, err := s.source.GetPlaylistTracks(ctx, playlistID)
 	if err != nil {
-		return fmt.Errorf("playlist tracks alınamadı: %v", err)
+		return nil, fmt.Errorf("playlist tracks alınamadı: %v", err)
 	}
 
 	// Update track count after getting tracks
-	spotifyPlaylist := spotify.Playlist{
-		ID: playlistID,
-		Name: playlistName,
-		TrackCount: len(tracks),
-	}
+	spotifyPlaylist.TrackCount = len(tracks)
 
 	fmt.Printf("Transferring playlist: %s (%d tracks)\n", spotifyPlaylist.Name, spotifyPlaylist.TrackCount)
 
-	// Check if playlist already exists on YouTube
-	exists, existingPlaylist, err := s.youtubeClient.PlaylistExists(spotifyPlaylist.Name)
+	title, description, err := options.render(spotifyPlaylist, fmt.Sprintf("Transferred from Spotify playlist: %s", playlistID))
 	if err != nil {
-		return fmt.Errorf("playlist existence check failed: %v", err)
+		return nil, err
 	}
 
-	var youtubePlaylist *youtube.YouTubePlaylist
-	if exists {
-		fmt.Printf("Playlist '%s' already exists on YouTube Music. Using existing playlist.\n", spotifyPlaylist.Name)
-		youtubePlaylist = existingPlaylist
-	} else {
-		// Create new playlist
-		if dryRun {
-			fmt.Printf("[DRY RUN] Would create playlist: %s\n", spotifyPlaylist.Name)
-			youtubePlaylist = &youtube.YouTubePlaylist{
-				Title:       spotifyPlaylist.Name,
-				Description: fmt.Sprintf("Transferred from Spotify playlist: %s", playlistID),
-			}
-		} else {
-			youtubePlaylist, err = s.youtubeClient.CreatePlaylist(spotifyPlaylist.Name, fmt.Sprintf("Transferred from Spotify playlist: %s", playlistID))
-			if err != nil {
-				return fmt.Errorf("YouTube playlist oluşturulamadı: %v", err)
-			}
-			fmt.Printf("Created YouTube playlist: %s\n", youtubePlaylist.Title)
-		}
+	youtubePlaylist, created, err := s.resolvePlaylist(ctx, title, description, options.Privacy, dryRun)
+	if errors.Is(err, errPlaylistExists) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Transfer tracks
-	result := s.transferTracks(tracks, youtubePlaylist, dryRun)
+	result := s.transferTracks(ctx, tracks, youtubePlaylist, dryRun)
+	result.SourcePlaylistID = playlistID
+	result.CreatedPlaylist = created
+	result.Playlist = &options
 	s.printTransferResult(result)
 
-	return nil
+	return []TransferResult{result}, ctx.Err()
 }
 
 // TransferAllPlaylists transfers all playlists from Spotify to YouTube Music
-func (s *Service) TransferAllPlaylists(dryRun bool) error {
+func (s *Service) TransferAllPlaylists(ctx context.Context, dryRun bool) ([]TransferResult, error) {
 	// Initialize clients
-	if err := s.initializeClients(); err != nil {
-		return fmt.Errorf("clients initialize edilemedi: %v", err)
+	if err := s.initializeClients(ctx, dryRun); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
 	}
 
 	// Get all playlists
-	playlists, err := s.spotifyClient.GetUserPlaylists()
+	playlists, err := s.source.GetUserPlaylists(ctx)
 	if err != nil {
-		return fmt.Errorf("playlists alınamadı: %v", err)
+		return nil, fmt.Errorf("playlists alınamadı: %v", err)
 	}
 
 	fmt.Printf("Found %d playlists to transfer\n", len(playlists))
 
 	var totalResults []TransferResult
 	for i, playlist := range playlists {
+		if ctx.Err() != nil {
+			break
+		}
 		fmt.Printf("\n[%d/%d] Processing: %s\n", i+1, len(playlists), playlist.Name)
 		
 		// Get tracks
-		tracks, err := s.spotifyClient.GetPlaylistTracks(playlist.ID)
+		tracks, err := s.source.GetPlaylistTracks(ctx, playlist.ID)
 		if err != nil {
 			fmt.Printf("Error getting tracks for %s: %v\n", playlist.Name, err)
 			continue
@@ -125,53 +300,47 @@ func (s *Service) TransferAllPlaylists(dryRun bool) error {
 		// Update playlist track count
 		playlist.TrackCount = len(tracks)
 
-		// Check if playlist exists
-		exists, existingPlaylist, err := s.youtubeClient.PlaylistExists(playlist.Name)
+		options := s.options.Playlist.dated()
+		title, description, err := options.render(playlist, playlist.Description)
 		if err != nil {
-			fmt.Printf("Error checking playlist existence: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			continue
 		}
 
-		var youtubePlaylist *youtube.YouTubePlaylist
-		if exists {
-			youtubePlaylist = existingPlaylist
-		} else {
-			if dryRun {
-				youtubePlaylist = &youtube.YouTubePlaylist{
-					Title:       playlist.Name,
-					Description: playlist.Description,
-				}
-			} else {
-				youtubePlaylist, err = s.youtubeClient.CreatePlaylist(playlist.Name, playlist.Description)
-				if err != nil {
-					fmt.Printf("Error creating YouTube playlist: %v\n", err)
-					continue
-				}
-			}
+		youtubePlaylist, created, err := s.resolvePlaylist(ctx, title, description, options.Privacy, dryRun)
+		if errors.Is(err, errPlaylistExists) {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
 		}
 
 		// Transfer tracks
-		result := s.transferTracks(tracks, youtubePlaylist, dryRun)
+		result := s.transferTracks(ctx, tracks, youtubePlaylist, dryRun)
+		result.SourcePlaylistID = playlist.ID
+		result.CreatedPlaylist = created
+		result.Playlist = &options
 		totalResults = append(totalResults, result)
 	}
 
 	// Print summary
 	s.printSummary(totalResults)
 
-	return nil
+	return totalResults, ctx.Err()
 }
 
 // TransferInteractive provides interactive playlist selection
-func (s *Service) TransferInteractive(dryRun bool) error {
+func (s *Service) TransferInteractive(ctx context.Context, dryRun bool) ([]TransferResult, error) {
 	// Initialize clients
-	if err := s.initializeClients(); err != nil {
-		return fmt.Errorf("clients initialize edilemedi: %v", err)
+	if err := s.initializeClients(ctx, dryRun); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
 	}
 
 	// Get all playlists
-	playlists, err := s.spotifyClient.GetUserPlaylists()
+	playlists, err := s.source.GetUserPlaylists(ctx)
 	if err != nil {
-		return fmt.Errorf("playlists alınamadı: %v", err)
+		return nil, fmt.Errorf("playlists alınamadı: %v", err)
 	}
 
 	// Create selection prompt
@@ -188,136 +357,303 @@ func (s *Service) TransferInteractive(dryRun bool) error {
 
 	index, _, err := prompt.Run()
 	if err != nil {
-		return fmt.Errorf("playlist selection failed: %v", err)
+		return nil, fmt.Errorf("playlist selection failed: %v", err)
 	}
 
 	selectedPlaylist := playlists[index]
-	return s.TransferPlaylist(selectedPlaylist.ID, selectedPlaylist.Name, dryRun)
+	return s.TransferPlaylist(ctx, selectedPlaylist.ID, selectedPlaylist.Name, dryRun)
 }
 
 // initializeClients initializes Spotify and YouTube clients
-func (s *Service) initializeClients() error {
+func (s *Service) initializeClients(ctx context.Context, dryRun bool) error {
 	var err error
 
-	if s.spotifyClient == nil {
-		s.spotifyClient, err = spotify.NewClient()
-		if err != nil {
-			return fmt.Errorf("Spotify client: %v", err)
+	if s.source == nil {
+		if s.spotifyClient == nil {
+			s.spotifyClient, err = spotify.NewClient()
+			if err != nil {
+				return fmt.Errorf("Spotify client: %v", err)
+			}
 		}
+		s.source = s.spotifyClient
 	}
 
 	if s.youtubeClient == nil {
-		s.youtubeClient, err = youtube.NewClient()
+		// Dry runs and file exports never write, so they only need read access
+		client, err := youtube.NewClient(ctx, youtube.Options{
+			ReadOnly: dryRun || s.destination.IsFile(),
+			APIKey:   s.options.YouTubeAPIKey,
+		})
 		if err != nil {
 			return fmt.Errorf("YouTube client: %v", err)
 		}
+		s.youtubeClient = client
 	}
 
 	return nil
 }
 
-// transferTracks transfers tracks from Spotify to YouTube Music
-func (s *Service) transferTracks(tracks []spotify.Track, youtubePlaylist *youtube.YouTubePlaylist, dryRun bool) TransferResult {
+// errPlaylistExists is returned for playlists skipped because they already
+// exist on the destination
+var errPlaylistExists = errors.New("playlist already exists")
+
+// resolvePlaylist finds or creates the YouTube playlist for a transfer and
+// reports whether it was created. In dry-run mode nothing is created and the
+// returned playlist has no ID. With skip_existing an existing playlist
+// yields errPlaylistExists.
+func (s *Service) resolvePlaylist(ctx context.Context, title, description, privacy string, dryRun bool) (*youtube.YouTubePlaylist, bool, error) {
+	// File exports never look at the YouTube account
+	if s.destination.IsFile() {
+		return &youtube.YouTubePlaylist{
+			Title:       title,
+			Description: description,
+		}, false, nil
+	}
+
+	if s.youtubeClient.Authenticated() {
+		exists, existingPlaylist, err := s.youtubeClient.PlaylistExists(ctx, title)
+		if err != nil {
+			return nil, false, fmt.Errorf("playlist existence check failed: %v", err)
+		}
+		if exists && s.skipExisting {
+			fmt.Printf("Playlist '%s' already exists on YouTube Music. Skipping it (use --skip-existing=false to add to it).\n", title)
+			return nil, false, errPlaylistExists
+		}
+		if exists {
+			fmt.Printf("Playlist '%s' already exists on YouTube Music. Using existing playlist.\n", title)
+			return existingPlaylist, false, nil
+		}
+	} else {
+		fmt.Printf("Cannot check whether '%s' exists with an API key; assuming it will be created.\n", title)
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would create playlist: %s\n", title)
+		return &youtube.YouTubePlaylist{
+			Title:       title,
+			Description: description,
+			Privacy:     privacy,
+		}, false, nil
+	}
+
+	youtubePlaylist, err := s.youtubeClient.CreatePlaylist(ctx, title, description, privacy)
+	if err != nil {
+		return nil, false, fmt.Errorf("YouTube playlist oluşturulamadı: %v", err)
+	}
+	fmt.Printf("Created YouTube playlist: %s\n", youtubePlaylist.Title)
+	return youtubePlaylist, true, nil
+}
+
+// transferTracks transfers tracks from Spotify to YouTube Music. When ctx is
+// cancelled the in-flight insert is allowed to finish and the partial result
+// is returned.
+func (s *Service) transferTracks(ctx context.Context, tracks []spotify.Track, youtubePlaylist *youtube.YouTubePlaylist, dryRun bool) TransferResult {
 	result := TransferResult{
 		PlaylistName:    youtubePlaylist.Title,
 		TotalTracks:     len(tracks),
 		YouTubePlaylist: youtubePlaylist,
+		Preference:      s.match.Prefer,
 	}
 
-	fmt.Printf("Transferring %d tracks...\n", len(tracks))
-
-	for i, track := range tracks {
-		fmt.Printf("[%d/%d] %s - %s", i+1, len(tracks), track.Artist, track.Name)
-		
-		// Search for track on YouTube
-		query := s.buildSearchQuery(track)
-		youtubeVideos, err := s.youtubeClient.SearchVideo(query)
-		if err != nil {
-			fmt.Printf(" [ERROR: %v]\n", err)
-			result.FailedTracks++
-			result.Errors = append(result.Errors, fmt.Sprintf("%s - %s: %v", track.Artist, track.Name, err))
-			continue
-		}
+	fmt.Printf("Transferring %d tracks (prefer: %s, workers: %d)...\n", len(tracks), s.match.Prefer, s.concurrency)
 
-		if len(youtubeVideos) == 0 {
-			fmt.Printf(" [NOT FOUND]\n")
-			result.FailedTracks++
-			result.Errors = append(result.Errors, fmt.Sprintf("%s - %s: No matching video found", track.Artist, track.Name))
-			continue
-		}
+	// Inserts are not cancelled half-way so the playlist never ends up
+	// with a request in an unknown state
+	insertCtx := context.WithoutCancel(ctx)
 
-		// Find best match
-		bestMatch := s.findBestMatch(track, youtubeVideos)
-		if bestMatch == nil {
-			fmt.Printf(" [NO GOOD MATCH]\n")
-			result.FailedTracks++
-			result.Errors = append(result.Errors, fmt.Sprintf("%s - %s: No good match found", track.Artist, track.Name))
-			continue
+	// Matches arrive in playlist order, so inserts keep the Spotify order
+	match := func(ctx context.Context, i int) TrackResult {
+		return s.matchTrack(ctx, tracks[i])
+	}
+	s.matchTracks(ctx, len(tracks), match, func(match TrackResult) {
+		if !dryRun && !s.destination.IsFile() {
+			s.insertTrack(insertCtx, youtubePlaylist.ID, &match)
 		}
+		result.record(match)
+	})
 
-		// Add to playlist
-		if !dryRun {
-			err = s.youtubeClient.AddVideoToPlaylist(youtubePlaylist.ID, bestMatch.ID)
-			if err != nil {
-				fmt.Printf(" [ADD ERROR: %v]\n", err)
-				result.FailedTracks++
-				result.Errors = append(result.Errors, fmt.Sprintf("%s - %s: %v", track.Artist, track.Name, err))
-				continue
-			}
-		}
+	if ctx.Err() != nil {
+		result.markInterrupted(tracks)
+	}
 
-		fmt.Printf(" [MATCHED: %s]\n", bestMatch.Title)
-		result.MatchedTracks++
-		
-		// Add delay to avoid rate limiting
-		time.Sleep(100 * time.Millisecond)
+	if s.destination.IsFile() {
+		s.fillDurations(insertCtx, &result)
 	}
 
 	return result
 }
 
-// buildSearchQuery builds a search query for YouTube
-func (s *Service) buildSearchQuery(track spotify.Track) string {
-	// Clean up the query
-	query := fmt.Sprintf("%s %s", track.Artist, track.Name)
-	
-	// Remove common words that might interfere with search
-	query = strings.ReplaceAll(query, "ft.", "")
-	query = strings.ReplaceAll(query, "feat.", "")
-	query = strings.ReplaceAll(query, "featuring", "")
-	
-	// Remove extra spaces
-	query = strings.Join(strings.Fields(query), " ")
-	
-	return query
+// insertTrack adds a matched track to the end of the playlist, turning
+// insert failures into StatusAddError
+func (s *Service) insertTrack(ctx context.Context, playlistID string, match *TrackResult) {
+	s.insertTrackAt(ctx, playlistID, match, -1)
 }
 
-// findBestMatch finds the best matching YouTube video
-func (s *Service) findBestMatch(track spotify.Track, videos []youtube.YouTubeVideo) *youtube.YouTubeVideo {
-	if len(videos) == 0 {
-		return nil
+// insertTrackAt adds a matched track at a zero-based position of the
+// playlist, or at the end for a negative position
+func (s *Service) insertTrackAt(ctx context.Context, playlistID string, match *TrackResult, position int) {
+	if match.Status != StatusMatched {
+		return
+	}
+	var itemID string
+	err := s.callYouTube(ctx, func() (err error) {
+		if position < 0 {
+			itemID, err = s.youtubeClient.AddVideoToPlaylist(ctx, playlistID, match.Video.ID)
+		} else {
+			itemID, err = s.youtubeClient.InsertVideoAt(ctx, playlistID, match.Video.ID, position)
+		}
+		return err
+	})
+	if err != nil {
+		match.Status = StatusAddError
+		match.Error = err.Error()
+		return
 	}
+	match.PlaylistItemID = itemID
+}
 
-	// Simple matching algorithm
-	trackTitle := strings.ToLower(track.Name)
-	trackArtist := strings.ToLower(track.Artist)
+// record prints the outcome of a track and adds it to the result
+func (r *TransferResult) record(match TrackResult) {
+	fmt.Printf("[%d/%d] %s - %s", match.Position, r.TotalTracks, match.Track.Artist, match.Track.Name)
 
-	for _, video := range videos {
-		videoTitle := strings.ToLower(video.Title)
-		
-		// Check if both title and artist are in the video title
-		if strings.Contains(videoTitle, trackTitle) && strings.Contains(videoTitle, trackArtist) {
-			return &video
+	switch match.Status {
+	case StatusMatched:
+		if match.SpotifyTrack != nil {
+			fmt.Printf(" [MATCHED: %s - %s]\n", match.SpotifyTrack.Artist, match.SpotifyTrack.Name)
+		} else {
+			fmt.Printf(" [MATCHED: %s]\n", match.Video.Title)
 		}
-		
-		// Check if just the title matches (for remixes, covers, etc.)
-		if strings.Contains(videoTitle, trackTitle) {
-			return &video
+		r.MatchedTracks++
+	case StatusNotFound:
+		fmt.Printf(" [NOT FOUND]\n")
+		r.FailedTracks++
+	case StatusNoGoodMatch:
+		fmt.Printf(" [NO GOOD MATCH]\n")
+		r.FailedTracks++
+	case StatusAddError:
+		fmt.Printf(" [ADD ERROR: %s]\n", match.Error)
+		r.FailedTracks++
+	default:
+		fmt.Printf(" [ERROR: %s]\n", match.Error)
+		r.FailedTracks++
+	}
+	r.Tracks = append(r.Tracks, match)
+}
+
+// markInterrupted keeps the tracks a cancelled run never processed, so they
+// can be retried. tracks are the tracks of the run in position order.
+func (r *TransferResult) markInterrupted(tracks []spotify.Track) {
+	done := make(map[int]bool, len(r.Tracks))
+	for _, track := range r.Tracks {
+		done[track.Position] = true
+	}
+	for i, track := range tracks {
+		if !done[i+1] {
+			r.Pending = append(r.Pending, track)
+		}
+	}
+	r.Interrupted = len(r.Pending) > 0
+}
+
+// matchTracks runs match for n tracks on a bounded pool of workers and calls
+// handle for every track in its original order. It stops handing out tracks
+// as soon as ctx is cancelled.
+func (s *Service) matchTracks(ctx context.Context, n int, match func(ctx context.Context, i int) TrackResult, handle func(match TrackResult)) {
+	workers := s.concurrency
+	if workers > n {
+		workers = n
+	}
+
+	// One buffered slot per track lets workers finish out of order while
+	// the caller still consumes results sequentially
+	results := make([]chan TrackResult, n)
+	for i := range results {
+		results[i] = make(chan TrackResult, 1)
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := 0; i < n; i++ {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
 		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				result := match(ctx, i)
+				result.Position = i + 1
+				results[i] <- result
+			}
+		}()
 	}
 
-	// Return the first result if no good match found
-	return &videos[0]
+	for i := 0; i < n; i++ {
+		var result TrackResult
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			return
+		}
+		// A search cut short by cancellation is not a real failure
+		if ctx.Err() != nil && result.Failed() {
+			return
+		}
+		handle(result)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// matchTrack searches YouTube for a track and picks the best candidate
+func (s *Service) matchTrack(ctx context.Context, track spotify.Track) TrackResult {
+	result := TrackResult{
+		Track: track,
+		Query: s.buildSearchQuery(track),
+	}
+
+	var youtubeVideos []youtube.YouTubeVideo
+	err := s.callYouTube(ctx, func() (err error) {
+		youtubeVideos, err = s.youtubeClient.SearchVideo(ctx, result.Query, s.searchOptions())
+		return err
+	})
+	if err != nil {
+		result.Status = StatusSearchError
+		result.Error = err.Error()
+		return result
+	}
+
+	if len(youtubeVideos) == 0 {
+		result.Status = StatusNotFound
+		result.Error = "No matching video found"
+		return result
+	}
+
+	bestMatch, score := s.bestMatch(track, youtubeVideos)
+	result.Score = score
+	if bestMatch == nil {
+		result.Status = StatusNoGoodMatch
+		result.Error = "No good match found"
+		return result
+	}
+
+	result.Video = bestMatch
+	result.Status = StatusMatched
+	return result
+}
+
+// searchOptions returns the YouTube search options for the configured preference
+func (s *Service) searchOptions() youtube.SearchOptions {
+	return youtube.SearchOptions{
+		// Both topic uploads and official videos live in the Music category
+		MusicOnly: s.match.Prefer == PreferTopic || s.match.Prefer == PreferOfficialVideo,
+	}
 }
 
 // printTransferResult prints the result of a transfer
@@ -325,17 +661,25 @@ func (s *Service) printTransferResult(result TransferResult) {
 	fmt.Printf("\n" + strings.Repeat("=", 50) + "\n")
 	fmt.Printf("Transfer Result: %s\n", result.PlaylistName)
 	fmt.Printf("Total Tracks: %d\n", result.TotalTracks)
+	fmt.Printf("Match Preference: %s\n", result.Preference)
+	if result.Interrupted {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Printf("%s\n", yellow(fmt.Sprintf("Interrupted after %d of %d tracks", result.MatchedTracks+result.FailedTracks, result.TotalTracks)))
+	}
 	
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
 	
 	fmt.Printf("Matched: %s\n", green(result.MatchedTracks))
 	fmt.Printf("Failed: %s\n", red(result.FailedTracks))
+	if len(result.Removed) > 0 {
+		fmt.Printf("Removed: %d\n", len(result.Removed))
+	}
 	
-	if len(result.Errors) > 0 {
+	if failures := result.Failures(); len(failures) > 0 {
 		fmt.Printf("\nErrors:\n")
-		for _, err := range result.Errors {
-			fmt.Printf("  - %s\n", err)
+		for _, failure := range failures {
+			fmt.Printf("  - %s - %s: %s\n", failure.Track.Artist, failure.Track.Name, failure.Error)
 		}
 	}
 	
@@ -361,11 +705,17 @@ func (s *Service) printSummary(results []TransferResult) {
 		green := color.New(color.FgGreen).SprintFunc()
 		red := color.New(color.FgRed).SprintFunc()
 		
-		fmt.Printf("%-30s | %s/%s | %s failed\n", 
+		status := ""
+		if result.Interrupted {
+			status = " (interrupted)"
+		}
+
+		fmt.Printf("%-30s | %s/%s | %s failed%s\n", 
 			result.PlaylistName, 
 			green(result.MatchedTracks), 
 			fmt.Sprintf("%d", result.TotalTracks),
-			red(result.FailedTracks))
+			red(result.FailedTracks),
+			status)
 	}
 	
 	fmt.Printf(strings.Repeat("-", 60) + "\n")
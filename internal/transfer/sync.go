@@ -0,0 +1,375 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// SyncMode selects how sync adds tracks that are new on Spotify
+type SyncMode string
+
+const (
+	// SyncAppend adds new tracks to the end of the YouTube playlist
+	SyncAppend SyncMode = "append"
+	// SyncOrdered inserts new tracks after the track they follow on Spotify
+	SyncOrdered SyncMode = "ordered"
+	// SyncOff creates the YouTube playlist once and never updates it
+	SyncOff SyncMode = "off"
+)
+
+// ParseSyncMode converts a sync mode name; empty means append
+func ParseSyncMode(value string) (SyncMode, error) {
+	switch SyncMode(strings.ToLower(strings.TrimSpace(value))) {
+	case SyncAppend, "":
+		return SyncAppend, nil
+	case SyncOrdered:
+		return SyncOrdered, nil
+	case SyncOff:
+		return SyncOff, nil
+	}
+	return "", fmt.Errorf("unknown sync mode %q (expected append, ordered or off)", value)
+}
+
+// SyncOptions configures how a mirror is brought up to date
+type SyncOptions struct {
+	Mode SyncMode
+	// Prune removes videos whose track is no longer in the Spotify playlist
+	Prune bool
+	// Metadata renames and redescribes the mirror when the Spotify playlist
+	// changed
+	Metadata bool
+	// Playlist overrides the templates the mirror was created with
+	Playlist PlaylistOptions
+	DryRun   bool
+}
+
+// SyncEntries brings the YouTube mirrors of manifest entries up to date.
+// mirrorOf returns the recorded mirror of a playlist and whether there is
+// one; playlists without one are transferred first.
+func (s *Service) SyncEntries(ctx context.Context, entries []ManifestEntry, mirrorOf func(playlistID string) (TransferResult, bool, error), dryRun bool) ([]TransferResult, error) {
+	if err := s.initializeClients(ctx, dryRun); err != nil {
+		return nil, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	var totalResults []TransferResult
+	for i, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("\n[%d/%d] Syncing: %s\n", i+1, len(entries), entry.Source)
+
+		entryService := s.forEntry(entry)
+		mirror, found, err := mirrorOf(entry.PlaylistID())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		if !found {
+			fmt.Println("No YouTube copy recorded yet, transferring the whole playlist")
+			results, err := entryService.transferPlaylist(ctx, entry.PlaylistID(), "", entry.Playlist(), dryRun)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			totalResults = append(totalResults, results...)
+			continue
+		}
+
+		options := entry.SyncOptions(dryRun)
+		if options.Mode == SyncOff {
+			fmt.Printf("Skipping %s: sync is off\n", mirror.PlaylistName)
+			continue
+		}
+		result, err := entryService.SyncPlaylist(ctx, entry.PlaylistID(), mirror, options)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		totalResults = append(totalResults, result)
+	}
+
+	s.printSummary(totalResults)
+
+	return totalResults, ctx.Err()
+}
+
+// SyncPlaylist adds the tracks that are new in a Spotify playlist to its
+// YouTube mirror and, with Prune, removes the videos recorded for tracks
+// that left it. The result only holds the new tracks.
+func (s *Service) SyncPlaylist(ctx context.Context, playlistID string, mirror TransferResult, opts SyncOptions) (TransferResult, error) {
+	if opts.Mode == SyncOff {
+		return TransferResult{}, fmt.Errorf("sync is off for %s", playlistID)
+	}
+	if err := s.initializeClients(ctx, opts.DryRun); err != nil {
+		return TransferResult{}, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	diff, err := s.DiffPlaylist(ctx, playlistID, mirror)
+	if err != nil {
+		return TransferResult{}, err
+	}
+
+	// An empty listing is more likely a scraping failure than an emptied
+	// playlist, and pruning would then delete the whole mirror
+	if opts.Prune && diff.SpotifyTracks == 0 {
+		return TransferResult{}, fmt.Errorf("Spotify returned no tracks for %s, refusing to prune", playlistID)
+	}
+
+	// Templates come from opts, then the ones the mirror was created with,
+	// then the service. Mirrors recorded without a date keep the first
+	// sync's date.
+	playlist := opts.Playlist
+	if mirror.Playlist != nil {
+		playlist = playlist.withDefaults(*mirror.Playlist)
+	}
+	playlist = playlist.withDefaults(s.options.Playlist).dated()
+
+	insertCtx := context.WithoutCancel(ctx)
+	youtubePlaylist := mirror.YouTubePlaylist
+	if opts.Metadata {
+		updated, err := s.syncMetadata(ctx, insertCtx, playlistID, diff.SpotifyTracks, youtubePlaylist, playlist, opts.DryRun)
+		if err != nil {
+			fmt.Printf("Warning: playlist details not synced: %v\n", err)
+		} else {
+			youtubePlaylist = updated
+		}
+	}
+	fmt.Printf("%s: %d new on Spotify, %d only on YouTube\n", youtubePlaylist.Title, len(diff.OnlyOnSpotify), len(diff.OnlyOnYouTube))
+
+	result := TransferResult{
+		PlaylistName:     youtubePlaylist.Title,
+		SourcePlaylistID: playlistID,
+		TotalTracks:      len(diff.OnlyOnSpotify),
+		YouTubePlaylist:  youtubePlaylist,
+		Preference:       s.match.Prefer,
+		Playlist:         &playlist,
+	}
+
+	order := newItemOrder(diff)
+
+	if opts.Prune {
+		for _, item := range prunable(diff, mirror.Tracks) {
+			if opts.DryRun {
+				fmt.Printf("[DRY RUN] Would remove #%d %s\n", item.Position+1, item.Video.Title)
+				continue
+			}
+			err := s.callYouTube(insertCtx, func() error {
+				return s.youtubeClient.DeletePlaylistItem(insertCtx, item.ID)
+			})
+			if err != nil {
+				fmt.Printf("Warning: %s could not be removed: %v\n", item.Video.Title, err)
+				continue
+			}
+			fmt.Printf("Removed #%d %s\n", item.Position+1, item.Video.Title)
+			order.remove(item.ID)
+			result.Removed = append(result.Removed, item)
+		}
+	}
+
+	tracks := make([]spotify.Track, len(diff.OnlyOnSpotify))
+	for i, entry := range diff.OnlyOnSpotify {
+		tracks[i] = entry.Track
+	}
+	match := func(ctx context.Context, i int) TrackResult {
+		return s.matchTrack(ctx, tracks[i])
+	}
+	s.matchTracks(ctx, len(tracks), match, func(match TrackResult) {
+		if !opts.DryRun {
+			spotifyPosition := diff.OnlyOnSpotify[match.Position-1].Position
+			if opts.Mode == SyncOrdered {
+				position := order.after(spotifyPosition)
+				s.insertTrackAt(insertCtx, youtubePlaylist.ID, &match, position)
+				order.insert(position, spotifyPosition, match.PlaylistItemID)
+			} else {
+				s.insertTrack(insertCtx, youtubePlaylist.ID, &match)
+			}
+		}
+		result.record(match)
+	})
+
+	if ctx.Err() != nil {
+		result.markInterrupted(tracks)
+	}
+
+	s.printTransferResult(result)
+
+	return result, ctx.Err()
+}
+
+// prunable returns the YouTube items that were recorded for a track which is
+// no longer in the Spotify playlist. Videos added by hand or never paired
+// are left alone.
+func prunable(diff PlaylistDiff, recorded []TrackResult) []youtube.PlaylistItem {
+	onSpotify := make(map[string]bool)
+	for _, pair := range diff.Pairs {
+		onSpotify[trackKey(pair.Track)] = true
+	}
+	for _, track := range diff.OnlyOnSpotify {
+		onSpotify[trackKey(track.Track)] = true
+	}
+
+	removed := make(map[string]bool)
+	for _, result := range recorded {
+		if !result.Failed() && result.Video != nil && !onSpotify[trackKey(result.Track)] {
+			removed[result.Video.ID] = true
+		}
+	}
+
+	var items []youtube.PlaylistItem
+	for _, item := range diff.OnlyOnYouTube {
+		if removed[item.Video.ID] {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// syncMetadata renders the title and description of a mirror from the
+// current Spotify playlist and updates the YouTube playlist if they changed.
+// Without a description template the description is left as it is.
+func (s *Service) syncMetadata(ctx, insertCtx context.Context, playlistID string, trackCount int, mirror *youtube.YouTubePlaylist, options PlaylistOptions, dryRun bool) (*youtube.YouTubePlaylist, error) {
+	info, err := s.source.GetPlaylistInfo(ctx, playlistID, "Unknown Playlist")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist info: %v", err)
+	}
+	if info.Name == "Unknown Playlist" {
+		return nil, fmt.Errorf("the name of %s could not be read from Spotify", playlistID)
+	}
+	info.TrackCount = trackCount
+
+	// The recorded details are stale if the playlist was edited on YouTube
+	current := mirror
+	err = s.callYouTube(ctx, func() error {
+		latest, err := s.youtubeClient.GetPlaylist(ctx, mirror.ID)
+		if err == nil {
+			current = latest
+		}
+		return err
+	})
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	title, description, err := options.render(info, current.Description)
+	if err != nil {
+		return nil, err
+	}
+	// Spotify does not always show the description, owner or cover, and a
+	// template using a missing one would blank it out on YouTube
+	if missing := missingFields(info); len(missing) > 0 {
+		if options.Name == "" && refersTo(options.Title, missing) {
+			title = current.Title
+		}
+		if refersTo(options.Description, missing) {
+			description = current.Description
+		}
+	}
+	if title == current.Title && description == current.Description {
+		return current, nil
+	}
+
+	if title != current.Title {
+		fmt.Printf("Title: %q -> %q\n", current.Title, title)
+	}
+	if description != current.Description {
+		fmt.Printf("Description: %q -> %q\n", current.Description, description)
+	}
+	updated := *current
+	updated.Title, updated.Description = title, description
+	if dryRun {
+		fmt.Println("[DRY RUN] Would update the playlist details")
+		return &updated, nil
+	}
+
+	err = s.callYouTube(insertCtx, func() error {
+		_, err := s.youtubeClient.UpdatePlaylist(insertCtx, current.ID, title, description)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// missingFields returns the template fields Spotify did not provide
+func missingFields(playlist spotify.Playlist) []string {
+	var missing []string
+	if playlist.Description == "" {
+		missing = append(missing, "Description")
+	}
+	if playlist.Owner == "" {
+		missing = append(missing, "Owner")
+	}
+	if playlist.Image == "" {
+		missing = append(missing, "Image")
+	}
+	return missing
+}
+
+// itemOrder tracks the items of a playlist while sync changes it, so new
+// tracks can be placed after the track they follow on Spotify
+type itemOrder struct {
+	// ids are the playlist item IDs in playlist order
+	ids []string
+	// bySpotify maps 1-based Spotify positions to their item ID
+	bySpotify map[int]string
+}
+
+func newItemOrder(diff PlaylistDiff) *itemOrder {
+	var items []youtube.PlaylistItem
+	order := &itemOrder{bySpotify: make(map[int]string)}
+	for _, pair := range diff.Pairs {
+		items = append(items, pair.Item)
+		order.bySpotify[pair.Position] = pair.Item.ID
+	}
+	items = append(items, diff.OnlyOnYouTube...)
+	sort.Slice(items, func(i, j int) bool { return items[i].Position < items[j].Position })
+	for _, item := range items {
+		order.ids = append(order.ids, item.ID)
+	}
+	return order
+}
+
+// after returns the zero-based playlist position following the item of the
+// closest earlier Spotify track, 0 when there is none
+func (o *itemOrder) after(spotifyPosition int) int {
+	for p := spotifyPosition - 1; p > 0; p-- {
+		id, ok := o.bySpotify[p]
+		if !ok {
+			continue
+		}
+		for i, itemID := range o.ids {
+			if itemID == id {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// insert records an item added at position for a Spotify track. Failed
+// inserts have no item ID and change nothing.
+func (o *itemOrder) insert(position, spotifyPosition int, id string) {
+	if id == "" {
+		return
+	}
+	o.ids = append(o.ids, "")
+	copy(o.ids[position+1:], o.ids[position:])
+	o.ids[position] = id
+	o.bySpotify[spotifyPosition] = id
+}
+
+// remove forgets a deleted item
+func (o *itemOrder) remove(id string) {
+	for i, itemID := range o.ids {
+		if itemID == id {
+			o.ids = append(o.ids[:i], o.ids[i+1:]...)
+			return
+		}
+	}
+}
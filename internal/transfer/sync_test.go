@@ -0,0 +1,270 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+func TestItemOrder(t *testing.T) {
+	// Spotify: 1 a, 2 new, 3 b, 4 new; YouTube: b, x, a
+	diff := PlaylistDiff{
+		Pairs: []DiffPair{
+			{DiffTrack: DiffTrack{Position: 1}, Item: youtube.PlaylistItem{ID: "a", Position: 2}},
+			{DiffTrack: DiffTrack{Position: 3}, Item: youtube.PlaylistItem{ID: "b", Position: 0}},
+		},
+		OnlyOnYouTube: []youtube.PlaylistItem{{ID: "x", Position: 1}},
+	}
+	order := newItemOrder(diff)
+	if !reflect.DeepEqual(order.ids, []string{"b", "x", "a"}) {
+		t.Fatalf("ids = %v", order.ids)
+	}
+
+	order.remove("x")
+	if got := order.after(2); got != 2 {
+		t.Errorf("after(2) = %d, want 2 (after a)", got)
+	}
+	order.insert(2, 2, "new2")
+	if got := order.after(4); got != 1 {
+		t.Errorf("after(4) = %d, want 1 (after b)", got)
+	}
+	order.insert(1, 4, "new4")
+	// A failed insert changes nothing
+	order.insert(0, 5, "")
+
+	if want := []string{"b", "new4", "a", "new2"}; !reflect.DeepEqual(order.ids, want) {
+		t.Errorf("ids = %v, want %v", order.ids, want)
+	}
+	if got := (&itemOrder{bySpotify: map[int]string{}}).after(3); got != 0 {
+		t.Errorf("after() in an empty playlist = %d, want 0", got)
+	}
+}
+
+func TestParseSyncMode(t *testing.T) {
+	for value, want := range map[string]SyncMode{"": SyncAppend, "Ordered": SyncOrdered, "off": SyncOff} {
+		if got, err := ParseSyncMode(value); err != nil || got != want {
+			t.Errorf("ParseSyncMode(%q) = %q, %v", value, got, err)
+		}
+	}
+	if _, err := ParseSyncMode("mirror"); err == nil {
+		t.Error("ParseSyncMode(mirror) should fail")
+	}
+}
+
+// fakeSource serves fixed Spotify playlists
+type fakeSource struct {
+	playlist spotify.Playlist
+	tracks   []spotify.Track
+}
+
+func (f *fakeSource) GetUserPlaylists(ctx context.Context) ([]spotify.Playlist, error) {
+	return []spotify.Playlist{f.playlist}, nil
+}
+
+func (f *fakeSource) GetPlaylistInfo(ctx context.Context, playlistID string, playlistName string) (spotify.Playlist, error) {
+	return f.playlist, nil
+}
+
+func (f *fakeSource) GetPlaylistTracks(ctx context.Context, playlistID string) ([]spotify.Track, error) {
+	return f.tracks, nil
+}
+
+// fakeYouTube keeps one playlist in memory and records every change
+type fakeYouTube struct {
+	playlist youtube.YouTubePlaylist
+	items    []youtube.PlaylistItem
+	// videos are returned by every search
+	videos   []youtube.YouTubeVideo
+	deleted  []string
+	inserted []string
+	updates  int
+}
+
+func (f *fakeYouTube) Authenticated() bool { return true }
+
+func (f *fakeYouTube) CreatePlaylist(ctx context.Context, title, description, privacy string) (*youtube.YouTubePlaylist, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeYouTube) UpdatePlaylist(ctx context.Context, playlistID, title, description string) (*youtube.YouTubePlaylist, error) {
+	f.updates++
+	f.playlist.Title, f.playlist.Description = title, description
+	playlist := f.playlist
+	return &playlist, nil
+}
+
+func (f *fakeYouTube) DeletePlaylist(ctx context.Context, playlistID string) error {
+	return errors.New("not supported")
+}
+
+func (f *fakeYouTube) GetPlaylist(ctx context.Context, playlistID string) (*youtube.YouTubePlaylist, error) {
+	playlist := f.playlist
+	return &playlist, nil
+}
+
+func (f *fakeYouTube) PlaylistExists(ctx context.Context, title string) (bool, *youtube.YouTubePlaylist, error) {
+	return false, nil, nil
+}
+
+func (f *fakeYouTube) GetPlaylistItems(ctx context.Context, playlistID string) ([]youtube.PlaylistItem, error) {
+	return f.items, nil
+}
+
+func (f *fakeYouTube) AddVideoToPlaylist(ctx context.Context, playlistID, videoID string) (string, error) {
+	f.inserted = append(f.inserted, videoID)
+	return "item-" + videoID, nil
+}
+
+func (f *fakeYouTube) InsertVideoAt(ctx context.Context, playlistID, videoID string, position int) (string, error) {
+	return f.AddVideoToPlaylist(ctx, playlistID, videoID)
+}
+
+func (f *fakeYouTube) DeletePlaylistItem(ctx context.Context, playlistItemID string) error {
+	f.deleted = append(f.deleted, playlistItemID)
+	return nil
+}
+
+func (f *fakeYouTube) SearchVideo(ctx context.Context, query string, opts youtube.SearchOptions) ([]youtube.YouTubeVideo, error) {
+	return f.videos, nil
+}
+
+func (f *fakeYouTube) GetVideoDetails(ctx context.Context, videoIDs []string) (map[string]youtube.VideoDetails, error) {
+	return map[string]youtube.VideoDetails{}, nil
+}
+
+// newFakeService returns a service reading from source and writing to client
+func newFakeService(source *fakeSource, client *fakeYouTube) *Service {
+	s := NewService(nil, Options{})
+	s.source = source
+	s.youtubeClient = client
+	return s
+}
+
+func TestSyncPlaylistPrune(t *testing.T) {
+	stays := spotify.Track{Artist: "Ed Sheeran", Name: "Shape of You"}
+	left := spotify.Track{Artist: "Dua Lipa", Name: "Levitating"}
+	added := spotify.Track{Artist: "Adele", Name: "Hello"}
+
+	client := &fakeYouTube{
+		playlist: youtube.YouTubePlaylist{ID: "PL1", Title: "Road Trip"},
+		items: []youtube.PlaylistItem{
+			playlistItem(0, "v1", "Shape of You", "Ed Sheeran - Topic"),
+			playlistItem(1, "v2", "Levitating", "Dua Lipa - Topic"),
+			// Added by hand, never part of a transfer
+			playlistItem(2, "v9", "Some Other Video", "Someone"),
+		},
+		videos: []youtube.YouTubeVideo{{ID: "v3", Title: "Hello", ChannelName: "Adele - Topic"}},
+	}
+	mirror := TransferResult{
+		YouTubePlaylist: &client.playlist,
+		Tracks: []TrackResult{
+			{Track: stays, Video: &youtube.YouTubeVideo{ID: "v1"}, Status: StatusMatched},
+			{Track: left, Video: &youtube.YouTubeVideo{ID: "v2"}, Status: StatusMatched},
+		},
+	}
+	source := &fakeSource{tracks: []spotify.Track{stays, added}}
+
+	result, err := newFakeService(source, client).SyncPlaylist(context.Background(), "abc", mirror, SyncOptions{Mode: SyncAppend, Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(client.deleted, []string{"item-v2"}) {
+		t.Errorf("deleted = %v, want only the item of the track that left", client.deleted)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].Video.ID != "v2" {
+		t.Errorf("Removed = %+v", result.Removed)
+	}
+	if !reflect.DeepEqual(client.inserted, []string{"v3"}) || result.MatchedTracks != 1 {
+		t.Errorf("inserted = %v, matched %d", client.inserted, result.MatchedTracks)
+	}
+}
+
+func TestSyncPlaylistRefusesToPruneEmptyListing(t *testing.T) {
+	client := &fakeYouTube{
+		playlist: youtube.YouTubePlaylist{ID: "PL1", Title: "Road Trip"},
+		items:    []youtube.PlaylistItem{playlistItem(0, "v1", "Shape of You", "Ed Sheeran - Topic")},
+	}
+	mirror := TransferResult{
+		YouTubePlaylist: &client.playlist,
+		Tracks: []TrackResult{
+			{Track: spotify.Track{Artist: "Ed Sheeran", Name: "Shape of You"}, Video: &youtube.YouTubeVideo{ID: "v1"}, Status: StatusMatched},
+		},
+	}
+
+	service := newFakeService(&fakeSource{}, client)
+	if _, err := service.SyncPlaylist(context.Background(), "abc", mirror, SyncOptions{Mode: SyncAppend, Prune: true}); err == nil {
+		t.Error("SyncPlaylist() should refuse to prune when Spotify lists no tracks")
+	}
+	if len(client.deleted) != 0 {
+		t.Errorf("deleted = %v", client.deleted)
+	}
+}
+
+func TestSyncMetadata(t *testing.T) {
+	options := PlaylistOptions{
+		Title:       "{{.Name}} ({{.Owner}})",
+		Description: "{{.Description}} Since {{.Date}}",
+		Created:     "2024-01-02",
+	}
+	tests := []struct {
+		name        string
+		spotify     spotify.Playlist
+		youtube     youtube.YouTubePlaylist
+		updates     int
+		title       string
+		description string
+	}{
+		{
+			name:        "Changed on Spotify",
+			spotify:     spotify.Playlist{Name: "Road Trip", Owner: "deniz", Description: "Songs for the road", Image: "cover"},
+			youtube:     youtube.YouTubePlaylist{ID: "PL1", Title: "Road Trip (deniz)", Description: "Old Since 2024-01-02"},
+			updates:     1,
+			title:       "Road Trip (deniz)",
+			description: "Songs for the road Since 2024-01-02",
+		},
+		{
+			name:        "Unchanged",
+			spotify:     spotify.Playlist{Name: "Road Trip", Owner: "deniz", Description: "Songs for the road", Image: "cover"},
+			youtube:     youtube.YouTubePlaylist{ID: "PL1", Title: "Road Trip (deniz)", Description: "Songs for the road Since 2024-01-02"},
+			title:       "Road Trip (deniz)",
+			description: "Songs for the road Since 2024-01-02",
+		},
+		{
+			name:        "Description not shown",
+			spotify:     spotify.Playlist{Name: "Road Trip 2", Owner: "deniz"},
+			youtube:     youtube.YouTubePlaylist{ID: "PL1", Title: "Road Trip (deniz)", Description: "Songs for the road Since 2024-01-02"},
+			updates:     1,
+			title:       "Road Trip 2 (deniz)",
+			description: "Songs for the road Since 2024-01-02",
+		},
+		{
+			name:        "Owner not shown",
+			spotify:     spotify.Playlist{Name: "Road Trip 2", Description: "Songs for the road"},
+			youtube:     youtube.YouTubePlaylist{ID: "PL1", Title: "Road Trip (deniz)", Description: "Songs for the road Since 2024-01-02"},
+			title:       "Road Trip (deniz)",
+			description: "Songs for the road Since 2024-01-02",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeYouTube{playlist: tt.youtube}
+			service := newFakeService(&fakeSource{playlist: tt.spotify}, client)
+
+			updated, err := service.syncMetadata(context.Background(), context.Background(), "abc", 1, &tt.youtube, options, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.updates != tt.updates {
+				t.Errorf("updates = %d, want %d", client.updates, tt.updates)
+			}
+			if updated.Title != tt.title || updated.Description != tt.description {
+				t.Errorf("syncMetadata() = %q, %q, want %q, %q", updated.Title, updated.Description, tt.title, tt.description)
+			}
+		})
+	}
+}
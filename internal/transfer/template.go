@@ -0,0 +1,176 @@
+package transfer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+// PlaylistOptions are the settings of one destination playlist
+type PlaylistOptions struct {
+	// Name is the literal playlist name and takes precedence over Title
+	Name string `json:"name,omitempty"`
+	// Title is a text/template for the playlist name, see TemplateData;
+	// empty keeps the source name
+	Title string `json:"title,omitempty"`
+	// Description is a text/template for the description of a created
+	// playlist, see TemplateData; empty keeps the default description
+	Description string `json:"description,omitempty"`
+	// Privacy of a created playlist: public, unlisted or private
+	Privacy string `json:"privacy,omitempty"`
+	// Created is the day of the first transfer as YYYY-MM-DD, kept so
+	// later syncs render the same .Date
+	Created string `json:"created,omitempty"`
+}
+
+// Validate checks the templates and privacy and normalizes the privacy
+func (o *PlaylistOptions) Validate() error {
+	if o.Title != "" {
+		if _, err := parseTemplate("title", o.Title); err != nil {
+			return err
+		}
+	}
+	if o.Description != "" {
+		if _, err := parseTemplate("description", o.Description); err != nil {
+			return err
+		}
+	}
+	if o.Privacy != "" {
+		privacy, err := youtube.ParsePrivacy(o.Privacy)
+		if err != nil {
+			return err
+		}
+		o.Privacy = privacy
+	}
+	return nil
+}
+
+// withDefaults fills the unset options from defaults
+func (o PlaylistOptions) withDefaults(defaults PlaylistOptions) PlaylistOptions {
+	if o.Name == "" && o.Title == "" {
+		o.Name, o.Title = defaults.Name, defaults.Title
+	}
+	if o.Description == "" {
+		o.Description = defaults.Description
+	}
+	if o.Privacy == "" {
+		o.Privacy = defaults.Privacy
+	}
+	if o.Created == "" {
+		o.Created = defaults.Created
+	}
+	return o
+}
+
+// dated sets Created to today unless it is set
+func (o PlaylistOptions) dated() PlaylistOptions {
+	if o.Created == "" {
+		o.Created = time.Now().Format("2006-01-02")
+	}
+	return o
+}
+
+// templated reports whether rendering needs more than the playlist name
+func (o PlaylistOptions) templated() bool {
+	return o.Title != "" || o.Description != ""
+}
+
+// render returns the title and description of the destination of a source
+// playlist. defaultDescription is used without a description template.
+func (o PlaylistOptions) render(playlist spotify.Playlist, defaultDescription string) (title, description string, err error) {
+	title, description = playlist.Name, defaultDescription
+	data := newTemplateData(playlist)
+	if o.Created != "" {
+		data.Date = o.Created
+	}
+	switch {
+	case o.Name != "":
+		title = o.Name
+	case o.Title != "":
+		if title, err = renderTemplate("title", o.Title, data); err != nil {
+			return "", "", err
+		}
+		if title == "" {
+			return "", "", fmt.Errorf("title template %q renders an empty title", o.Title)
+		}
+	}
+	if o.Description != "" {
+		if description, err = renderTemplate("description", o.Description, data); err != nil {
+			return "", "", err
+		}
+	}
+	return title, description, nil
+}
+
+// refersTo reports whether a template uses one of the given fields
+func refersTo(text string, fields []string) bool {
+	for _, field := range fields {
+		if regexp.MustCompile(`\.` + field + `\b`).MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateData is what playlist templates can refer to, e.g.
+// "{{.Name}} by {{.Owner}} ({{.TrackCount}} tracks)"
+type TemplateData struct {
+	Name        string
+	Description string
+	Owner       string
+	TrackCount  int
+	// URL is the Spotify link of the source playlist
+	URL string
+	ID  string
+	// Image is the cover image URL of the source playlist, if known.
+	// YouTube playlists take their thumbnail from a video, so the cover can
+	// only be linked from the description.
+	Image string
+	// Date is the day of the first transfer as YYYY-MM-DD
+	Date string
+}
+
+// newTemplateData describes a source playlist for templates
+func newTemplateData(playlist spotify.Playlist) TemplateData {
+	return TemplateData{
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		Owner:       playlist.Owner,
+		TrackCount:  playlist.TrackCount,
+		URL:         "https://open.spotify.com/playlist/" + playlist.ID,
+		ID:          playlist.ID,
+		Image:       playlist.Image,
+		Date:        time.Now().Format("2006-01-02"),
+	}
+}
+
+// parseTemplate checks a playlist template. Unknown fields are errors.
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %v", name, err)
+	}
+	// Executing against empty data catches misspelled fields up front
+	if err := tmpl.Execute(new(strings.Builder), TemplateData{}); err != nil {
+		return nil, fmt.Errorf("invalid %s template: %v", name, err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate executes a playlist template
+func renderTemplate(name, text string, data TemplateData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%s template: %v", name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
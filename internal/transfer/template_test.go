@@ -0,0 +1,63 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"spotomusic/internal/spotify"
+)
+
+func TestPlaylistOptionsRender(t *testing.T) {
+	playlist := spotify.Playlist{ID: "abc", Name: "Road Trip", Owner: "deniz", TrackCount: 42}
+
+	title, description, err := PlaylistOptions{}.render(playlist, "default")
+	if err != nil || title != "Road Trip" || description != "default" {
+		t.Errorf("render() without templates = %q, %q, %v", title, description, err)
+	}
+
+	options := PlaylistOptions{
+		Title:       "{{.Name}} ({{.Owner}})",
+		Description: "{{.TrackCount}} tracks from {{.URL}} on {{.Date}}\n",
+	}
+	title, description, err = options.render(playlist, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "Road Trip (deniz)" {
+		t.Errorf("title = %q", title)
+	}
+	want := "42 tracks from https://open.spotify.com/playlist/abc on " + time.Now().Format("2006-01-02")
+	if description != want {
+		t.Errorf("description = %q, want %q", description, want)
+	}
+
+	if _, _, err := (PlaylistOptions{Title: "{{.Description}}"}).render(playlist, ""); err == nil {
+		t.Error("render() should reject an empty title")
+	}
+
+	// A literal name is not parsed as a template and wins over the title
+	options = PlaylistOptions{Name: "{{ braces }}"}.withDefaults(PlaylistOptions{Title: "{{.Name}} (copy)"})
+	if title, _, err := options.render(playlist, ""); err != nil || title != "{{ braces }}" {
+		t.Errorf("render() with a name = %q, %v", title, err)
+	}
+}
+
+func TestPlaylistOptionsValidate(t *testing.T) {
+	options := PlaylistOptions{Title: "{{.Name}}", Privacy: " Unlisted"}
+	if err := options.Validate(); err != nil || options.Privacy != "unlisted" {
+		t.Errorf("Validate() = %v, privacy %q", err, options.Privacy)
+	}
+
+	for _, options := range []PlaylistOptions{
+		{Title: "{{.Title}}"},
+		{Description: "{{.Name"},
+		{Privacy: "friends"},
+	} {
+		if err := options.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", options)
+		} else if !strings.Contains(err.Error(), "template") && options.Privacy == "" {
+			t.Errorf("Validate(%+v) = %v", options, err)
+		}
+	}
+}
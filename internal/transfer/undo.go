@@ -0,0 +1,95 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"spotomusic/internal/youtube"
+)
+
+// UndoResult summarises what was removed for one playlist of a run
+type UndoResult struct {
+	PlaylistName    string
+	DeletedPlaylist bool
+	RemovedItems    int
+	Errors          []string
+}
+
+// UndoPlaylist reverts the changes a transfer made to one YouTube playlist.
+// Playlists created by the run are deleted entirely; for existing playlists
+// only the items inserted by the run are removed. Items and playlists that
+// are already gone count as removed, so a partly failed undo can be rerun.
+// With dryRun set nothing is changed and the planned deletions are printed
+// instead.
+func (s *Service) UndoPlaylist(ctx context.Context, result TransferResult, dryRun bool) (UndoResult, error) {
+	undo := UndoResult{PlaylistName: result.PlaylistName}
+	// YouTubePlaylist is the source of reverse transfers and must never be
+	// touched
+	if result.Direction == DirectionToSpotify {
+		return undo, fmt.Errorf("undoing YouTube to Spotify transfers is not supported")
+	}
+	if result.YouTubePlaylist == nil || result.YouTubePlaylist.ID == "" {
+		return undo, nil
+	}
+	playlistID := result.YouTubePlaylist.ID
+
+	if !dryRun && s.youtubeClient == nil {
+		if err := s.initializeClients(ctx, false); err != nil {
+			return undo, fmt.Errorf("clients initialize edilemedi: %v", err)
+		}
+	}
+
+	if result.CreatedPlaylist {
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would delete playlist: %s (%s)\n", result.PlaylistName, playlistID)
+			undo.DeletedPlaylist = true
+			return undo, nil
+		}
+		err := s.youtubeClient.DeletePlaylist(ctx, playlistID)
+		switch {
+		case youtube.IsNotFound(err):
+			// Removed by an earlier, partly failed undo or by hand
+			fmt.Printf("Playlist already deleted: %s\n", result.PlaylistName)
+		case err != nil:
+			return undo, err
+		default:
+			fmt.Printf("Deleted playlist: %s\n", result.PlaylistName)
+		}
+		undo.DeletedPlaylist = true
+		return undo, nil
+	}
+
+	for _, track := range result.Tracks {
+		if track.PlaylistItemID == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			return undo, ctx.Err()
+		}
+
+		name := fmt.Sprintf("%s - %s", track.Track.Artist, track.Track.Name)
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would remove from %s: %s\n", result.PlaylistName, name)
+			undo.RemovedItems++
+			continue
+		}
+
+		if err := s.limiter.Wait(ctx); err != nil {
+			return undo, err
+		}
+		err := s.youtubeClient.DeletePlaylistItem(ctx, track.PlaylistItemID)
+		switch {
+		case youtube.IsNotFound(err):
+			// Removed by an earlier, partly failed undo or by hand
+			fmt.Printf("Already removed from %s: %s\n", result.PlaylistName, name)
+		case err != nil:
+			undo.Errors = append(undo.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		default:
+			fmt.Printf("Removed from %s: %s\n", result.PlaylistName, name)
+		}
+		undo.RemovedItems++
+	}
+
+	return undo, nil
+}
@@ -0,0 +1,339 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"spotomusic/internal/youtube"
+)
+
+// Verdict is the outcome of checking one item of a YouTube mirror
+type Verdict string
+
+const (
+	VerdictOK         Verdict = "ok"
+	VerdictSuspicious Verdict = "suspicious"
+	VerdictDeleted    Verdict = "deleted"
+	VerdictPrivate    Verdict = "private"
+	// VerdictBlocked marks videos that cannot be played in the checked region
+	VerdictBlocked Verdict = "region_blocked"
+)
+
+// Unavailable reports whether the video can no longer be played
+func (v Verdict) Unavailable() bool {
+	return v == VerdictDeleted || v == VerdictPrivate || v == VerdictBlocked
+}
+
+// VerifyOptions controls what VerifyPlaylist checks and fixes
+type VerifyOptions struct {
+	// Fix replaces flagged items with better candidates in place
+	Fix bool
+	// DryRun only reports the replacements Fix would make
+	DryRun bool
+	// UnavailableOnly skips rescoring and only looks for deleted, private
+	// and region-blocked videos
+	UnavailableOnly bool
+	// Region is an ISO 3166-1 alpha-2 code; videos blocked there are
+	// flagged. Empty disables the region check.
+	Region string
+}
+
+// durationTolerance is how far a video may be off the track length before
+// it counts as a different recording; longer tracks get durationSlack of
+// their length instead
+const (
+	durationTolerance = 20 * time.Second
+	durationSlack     = 0.10
+)
+
+// ItemCheck is the audit result of one mirrored track
+type ItemCheck struct {
+	DiffPair
+	Score   int      `json:"score"`
+	Verdict Verdict  `json:"verdict"`
+	Reasons []string `json:"reasons,omitempty"`
+	// Replacement is the better candidate found with fixing enabled
+	Replacement *youtube.YouTubeVideo `json:"replacement,omitempty"`
+	// ReplacementItemID is set once the replacement was inserted
+	ReplacementItemID string `json:"replacement_item_id,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// VerifyResult is the audit of one YouTube mirror
+type VerifyResult struct {
+	SourcePlaylistID  string      `json:"source_playlist_id"`
+	YouTubePlaylistID string      `json:"youtube_playlist_id"`
+	Checks            []ItemCheck `json:"checks"`
+	// Unpaired counts Spotify tracks without a YouTube item; see diff
+	Unpaired int `json:"unpaired"`
+	// Orphaned are unavailable items whose source track is unknown, so
+	// they cannot be replaced automatically
+	Orphaned []youtube.PlaylistItem `json:"orphaned,omitempty"`
+}
+
+// Flagged returns the checks that did not pass
+func (r VerifyResult) Flagged() []ItemCheck {
+	var flagged []ItemCheck
+	for _, check := range r.Checks {
+		if check.Verdict != VerdictOK {
+			flagged = append(flagged, check)
+		}
+	}
+	return flagged
+}
+
+// Replaced returns the checks whose item was swapped for a new video
+func (r VerifyResult) Replaced() []ItemCheck {
+	var replaced []ItemCheck
+	for _, check := range r.Checks {
+		if check.ReplacementItemID != "" {
+			replaced = append(replaced, check)
+		}
+	}
+	return replaced
+}
+
+// VerifyPlaylist rescores every item of the YouTube mirror of a playlist
+// against its Spotify track and flags suspicious and unavailable videos.
+// See VerifyOptions for fixing them.
+func (s *Service) VerifyPlaylist(ctx context.Context, playlistID string, mirror TransferResult, opts VerifyOptions) (VerifyResult, error) {
+	if mirror.YouTubePlaylist == nil || mirror.YouTubePlaylist.ID == "" {
+		return VerifyResult{}, fmt.Errorf("no YouTube playlist recorded for %s", playlistID)
+	}
+	if err := s.initializeClients(ctx, !opts.Fix || opts.DryRun); err != nil {
+		return VerifyResult{}, fmt.Errorf("clients initialize edilemedi: %v", err)
+	}
+
+	tracks, err := s.source.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("playlist tracks alınamadı: %v", err)
+	}
+	items, err := s.youtubeClient.GetPlaylistItems(ctx, mirror.YouTubePlaylist.ID)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	diff := DiffPlaylist(tracks, mirror.Tracks, items)
+
+	var ids []string
+	for _, pair := range diff.Pairs {
+		ids = append(ids, pair.Item.Video.ID)
+	}
+	details, err := s.youtubeClient.GetVideoDetails(ctx, ids)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+
+	result := VerifyResult{
+		SourcePlaylistID:  playlistID,
+		YouTubePlaylistID: mirror.YouTubePlaylist.ID,
+		Unpaired:          len(diff.OnlyOnSpotify),
+	}
+	for _, pair := range diff.Pairs {
+		detail, found := details[pair.Item.Video.ID]
+		check := s.checkItem(pair, detail, found, opts.Region)
+		if opts.UnavailableOnly && check.Verdict == VerdictSuspicious {
+			check.Verdict, check.Reasons = VerdictOK, nil
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	for _, item := range diff.OnlyOnYouTube {
+		if item.Unavailable() {
+			result.Orphaned = append(result.Orphaned, item)
+		}
+	}
+
+	if opts.Fix {
+		insertCtx := context.WithoutCancel(ctx)
+		for i := range result.Checks {
+			if ctx.Err() != nil {
+				break
+			}
+			if result.Checks[i].Verdict != VerdictOK {
+				s.replaceItem(ctx, insertCtx, mirror.YouTubePlaylist.ID, &result.Checks[i], opts)
+			}
+		}
+	}
+
+	return result, ctx.Err()
+}
+
+// checkItem rescores a mirrored item on title, channel and duration
+func (s *Service) checkItem(pair DiffPair, detail youtube.VideoDetails, found bool, region string) ItemCheck {
+	check := ItemCheck{DiffPair: pair, Verdict: VerdictOK}
+
+	switch {
+	case pair.Item.PrivacyStatus == "private" || pair.Item.Video.Title == "Private video":
+		check.Verdict = VerdictPrivate
+		check.Reasons = append(check.Reasons, "video is private")
+		return check
+	case !found || pair.Item.Unavailable():
+		check.Verdict = VerdictDeleted
+		check.Reasons = append(check.Reasons, "video was deleted")
+		return check
+	case detail.PrivacyStatus == "private":
+		check.Verdict = VerdictPrivate
+		check.Reasons = append(check.Reasons, "video is private")
+		return check
+	case detail.UploadStatus != "" && detail.UploadStatus != "processed" && detail.UploadStatus != "uploaded":
+		check.Verdict = VerdictDeleted
+		check.Reasons = append(check.Reasons, "video was "+detail.UploadStatus)
+		return check
+	case blockedIn(detail, region):
+		check.Verdict = VerdictBlocked
+		check.Reasons = append(check.Reasons, "video is not available in "+strings.ToUpper(region))
+		return check
+	}
+
+	video := pair.Item.Video
+	if detail.ChannelName != "" {
+		video.ChannelID, video.ChannelName = detail.ChannelID, detail.ChannelName
+	}
+	check.Score = s.scoreVideo(pair.Track, video)
+
+	if check.Score < minMatchScore {
+		check.Reasons = append(check.Reasons, fmt.Sprintf("title and artist do not match (score %d)", check.Score))
+	} else if unwantedPenalty(pair.Track.Name, video.Title) > 0 {
+		check.Reasons = append(check.Reasons, "looks like a cover, live or remixed version")
+	}
+	if reason := durationMismatch(pair.Track.Duration, detail.Duration); reason != "" {
+		check.Reasons = append(check.Reasons, reason)
+	}
+	if len(check.Reasons) > 0 {
+		check.Verdict = VerdictSuspicious
+	}
+	return check
+}
+
+// blockedIn reports whether a video cannot be played in region
+func blockedIn(detail youtube.VideoDetails, region string) bool {
+	if region == "" {
+		return false
+	}
+	for _, blocked := range detail.BlockedRegions {
+		if strings.EqualFold(blocked, region) {
+			return true
+		}
+	}
+	if len(detail.AllowedRegions) == 0 {
+		return false
+	}
+	for _, allowed := range detail.AllowedRegions {
+		if strings.EqualFold(allowed, region) {
+			return false
+		}
+	}
+	return true
+}
+
+// durationMismatch describes how far a video is off the track length, or
+// returns "" when either length is unknown or they are close enough
+func durationMismatch(trackMillis int, video time.Duration) string {
+	track := time.Duration(trackMillis) * time.Millisecond
+	if track <= 0 || video <= 0 {
+		return ""
+	}
+
+	tolerance := durationTolerance
+	if slack := time.Duration(float64(track) * durationSlack); slack > tolerance {
+		tolerance = slack
+	}
+	delta := video - track
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta <= tolerance {
+		return ""
+	}
+	return fmt.Sprintf("duration %s differs from the track's %s", formatDuration(video), formatDuration(track))
+}
+
+// formatDuration prints a duration as m:ss
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// replaceItem searches a better candidate for a flagged item and swaps it in
+// at the same position: the new video is inserted first, then the old item
+// is removed
+func (s *Service) replaceItem(ctx, insertCtx context.Context, playlistID string, check *ItemCheck, opts VerifyOptions) {
+	match := s.matchTrack(ctx, check.Track)
+	switch {
+	case match.Failed():
+		check.Error = "no replacement found: " + match.Error
+		return
+	case match.Video.ID == check.Item.Video.ID:
+		check.Error = "no better candidate than the current video"
+		return
+	case check.Verdict == VerdictSuspicious && match.Score <= check.Score:
+		check.Error = fmt.Sprintf("best candidate scores %d, not better than %d", match.Score, check.Score)
+		return
+	}
+
+	// A replacement for a blocked or deleted video must itself be playable
+	if check.Verdict.Unavailable() {
+		details, err := s.youtubeClient.GetVideoDetails(ctx, []string{match.Video.ID})
+		if err != nil {
+			check.Error = err.Error()
+			return
+		}
+		detail, found := details[match.Video.ID]
+		if !found || detail.PrivacyStatus == "private" || blockedIn(detail, opts.Region) {
+			check.Error = "the best candidate " + match.Video.Title + " is unavailable too"
+			return
+		}
+	}
+	check.Replacement = match.Video
+
+	if opts.DryRun {
+		fmt.Printf("[DRY RUN] Would replace #%d %s with %s\n", check.Item.Position+1, check.Item.Video.Title, match.Video.Title)
+		return
+	}
+
+	var itemID string
+	err := s.callYouTube(insertCtx, func() (err error) {
+		itemID, err = s.youtubeClient.InsertVideoAt(insertCtx, playlistID, match.Video.ID, check.Item.Position)
+		return err
+	})
+	if err != nil {
+		check.Error = err.Error()
+		return
+	}
+	// A duplicate insert is ignored by the client and yields no item; the
+	// old item is only removed once the new one is confirmed
+	if itemID == "" {
+		check.Error = match.Video.Title + " could not be added, the video may already be in the playlist"
+		return
+	}
+	check.ReplacementItemID = itemID
+
+	if err := s.youtubeClient.DeletePlaylistItem(insertCtx, check.Item.ID); err != nil {
+		check.Error = fmt.Sprintf("replacement added but the old item remains: %v", err)
+		return
+	}
+	fmt.Printf("Replaced #%d %s with %s\n", check.Item.Position+1, check.Item.Video.Title, match.Video.Title)
+}
+
+// ReplacementResults turns the replaced items into track results so the
+// new track to video mapping can be recorded like a transfer
+func (r VerifyResult) ReplacementResults(playlist *youtube.YouTubePlaylist) TransferResult {
+	result := TransferResult{
+		PlaylistName:     playlist.Title,
+		SourcePlaylistID: r.SourcePlaylistID,
+		YouTubePlaylist:  playlist,
+	}
+	for _, check := range r.Replaced() {
+		result.Tracks = append(result.Tracks, TrackResult{
+			Position:       check.Position,
+			Track:          check.Track,
+			Video:          check.Replacement,
+			PlaylistItemID: check.ReplacementItemID,
+			Status:         StatusMatched,
+		})
+	}
+	result.TotalTracks = len(result.Tracks)
+	result.MatchedTracks = len(result.Tracks)
+	return result
+}
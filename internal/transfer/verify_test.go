@@ -0,0 +1,60 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"spotomusic/internal/spotify"
+	"spotomusic/internal/youtube"
+)
+
+func TestCheckItem(t *testing.T) {
+	service := NewService(nil, Options{})
+	track := spotify.Track{Artist: "Ed Sheeran", Name: "Shape of You", Duration: 233712}
+	pair := func(title, channel string) DiffPair {
+		return DiffPair{
+			DiffTrack: DiffTrack{Position: 1, Track: track},
+			Item:      playlistItem(0, "v1", title, channel),
+		}
+	}
+	details := youtube.VideoDetails{ID: "v1", Duration: 234 * time.Second, PrivacyStatus: "public", UploadStatus: "processed"}
+
+	tests := []struct {
+		name    string
+		pair    DiffPair
+		detail  youtube.VideoDetails
+		found   bool
+		verdict Verdict
+	}{
+		{name: "Good match", pair: pair("Shape of You", "Ed Sheeran - Topic"), detail: details, found: true, verdict: VerdictOK},
+		{name: "Cover", pair: pair("Shape of You - Ed Sheeran (Cover)", "Someone"), detail: details, found: true, verdict: VerdictSuspicious},
+		{name: "Wrong song", pair: pair("Perfect", "Ed Sheeran - Topic"), detail: details, found: true, verdict: VerdictSuspicious},
+		{name: "Wrong length", pair: pair("Shape of You", "Ed Sheeran - Topic"), detail: youtube.VideoDetails{Duration: 10 * time.Minute}, found: true, verdict: VerdictSuspicious},
+		{name: "Deleted", pair: pair("Deleted video", ""), found: false, verdict: VerdictDeleted},
+		{name: "Private", pair: pair("Private video", ""), found: false, verdict: VerdictPrivate},
+		{name: "Blocked", pair: pair("Shape of You", "Ed Sheeran - Topic"), detail: youtube.VideoDetails{BlockedRegions: []string{"tr"}}, found: true, verdict: VerdictBlocked},
+		{name: "Not allowed", pair: pair("Shape of You", "Ed Sheeran - Topic"), detail: youtube.VideoDetails{AllowedRegions: []string{"US"}}, found: true, verdict: VerdictBlocked},
+		{name: "Allowed", pair: pair("Shape of You", "Ed Sheeran - Topic"), detail: youtube.VideoDetails{AllowedRegions: []string{"TR"}}, found: true, verdict: VerdictOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := service.checkItem(tt.pair, tt.detail, tt.found, "TR")
+			if check.Verdict != tt.verdict {
+				t.Errorf("checkItem() verdict = %s (%v), want %s", check.Verdict, check.Reasons, tt.verdict)
+			}
+		})
+	}
+}
+
+func TestDurationMismatch(t *testing.T) {
+	if reason := durationMismatch(233712, 250*time.Second); reason != "" {
+		t.Errorf("Expected 16s off to be tolerated, got %q", reason)
+	}
+	if reason := durationMismatch(233712, 300*time.Second); reason == "" {
+		t.Error("Expected 66s off to be flagged")
+	}
+	if reason := durationMismatch(0, 300*time.Second); reason != "" {
+		t.Errorf("Unknown track length must not be flagged, got %q", reason)
+	}
+}
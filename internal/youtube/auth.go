@@ -2,94 +2,199 @@ package youtube
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"os"
-	"path/filepath"
+
+	"spotomusic/internal/auth"
+	"spotomusic/internal/profile"
+	"spotomusic/internal/secret"
 
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/youtube/v3"
 )
 
-// authenticateYouTube performs OAuth2 authentication flow for YouTube
-func authenticateYouTube(config *oauth2.Config) (*oauth2.Token, error) {
-	// Set redirect URI to localhost
-	config.RedirectURL = "http://localhost:8081"
-	
-	// Start HTTP server for callback
-	http.HandleFunc("/", completeYouTubeAuth(config))
+// scope returns the OAuth scope the options need and the token file that
+// holds it
+func (o Options) scope() (string, string) {
+	if o.ReadOnly {
+		return youtube.YoutubeReadonlyScope, youtubeReadOnlyTokenFile
+	}
+	return youtube.YoutubeScope, youtubeTokenFile
+}
 
-	go func() {
-		err := http.ListenAndServe(":8081", nil)
-		if err != nil {
-			fmt.Printf("HTTP server error: %v\n", err)
+// oauthConfig loads the OAuth client credentials from
+// YOUTUBE_CREDENTIALS_JSON, the configured credentials file or the secret
+// store of the active profile
+func oauthConfig(scope string) (*oauth2.Config, error) {
+	credentialsJSON := []byte(os.Getenv("YOUTUBE_CREDENTIALS_JSON"))
+	if len(credentialsJSON) == 0 {
+		active := profile.Active()
+		var err error
+		if active.YouTubeCredentialsFile != "" {
+			credentialsJSON, err = os.ReadFile(active.YouTubeCredentialsFile)
+			if err != nil {
+				return nil, fmt.Errorf("YouTube credentials dosyası okunamadı: %v", err)
+			}
+		} else {
+			credentialsJSON, err = active.Secrets.Get(credentialsSecret)
+			if errors.Is(err, secret.ErrNotFound) {
+				return nil, fmt.Errorf("YouTube credentials bulunamadı. 'spotomusic auth credentials youtube <file>' ile kaydedin veya %s dosyasını oluşturun", active.Secrets.Location(credentialsSecret))
+			}
+			if err != nil {
+				return nil, err
+			}
 		}
-	}()
-
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Lütfen aşağıdaki URL'yi tarayıcınızda açın:\n%s\n\n", authURL)
+	}
 
-	// Wait for callback
-	token := <-youtubeAuthCh
+	config, err := google.ConfigFromJSON(credentialsJSON, scope)
+	if err != nil {
+		return nil, fmt.Errorf("credentials parse edilemedi: %v", err)
+	}
+	// Client files do not name the device endpoint
+	config.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	return config, nil
+}
 
-	return token, nil
+// StoreCredentials saves an OAuth client file downloaded from the Google
+// Cloud Console in the secret store of the active profile
+func StoreCredentials(credentialsJSON []byte) error {
+	if _, err := google.ConfigFromJSON(credentialsJSON, youtube.YoutubeScope); err != nil {
+		return fmt.Errorf("credentials parse edilemedi: %v", err)
+	}
+	if err := profile.Active().Secrets.Set(credentialsSecret, credentialsJSON); err != nil {
+		return fmt.Errorf("YouTube credentials kaydedilemedi: %v", err)
+	}
+	return nil
 }
 
-var youtubeAuthCh = make(chan *oauth2.Token)
+// Login runs flow and saves the resulting token, replacing any saved one.
+// Device logins need an OAuth client of type "TVs and Limited Input devices".
+func Login(ctx context.Context, opts Options, flow auth.Flow) error {
+	scope, name := opts.scope()
 
-func completeYouTubeAuth(config *oauth2.Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		code := r.FormValue("code")
-		if code == "" {
-			http.Error(w, "Authorization code not found", http.StatusBadRequest)
-			return
-		}
+	config, err := oauthConfig(scope)
+	if err != nil {
+		return err
+	}
+	file, err := tokenFile(name)
+	if err != nil {
+		return err
+	}
+	token, err := flow.Login(ctx, config)
+	if err != nil {
+		return fmt.Errorf("YouTube authentication failed: %w", err)
+	}
+	if err := file.Save(token); err != nil {
+		return fmt.Errorf("YouTube token kaydedilemedi: %v", err)
+	}
+	return nil
+}
 
-		token, err := config.Exchange(context.Background(), code)
+// Logout removes the saved tokens of both scopes
+func Logout() error {
+	for _, name := range []string{youtubeTokenFile, youtubeReadOnlyTokenFile} {
+		file, err := tokenFile(name)
 		if err != nil {
-			http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
-			fmt.Printf("Token exchange error: %v\n", err)
-			return
+			return err
+		}
+		if err := file.Remove(); err != nil {
+			return fmt.Errorf("YouTube token silinemedi: %v", err)
 		}
-
-		fmt.Fprintf(w, "YouTube authentication completed! You can close this window.")
-		youtubeAuthCh <- token
 	}
+	return nil
 }
 
-// loadYouTubeToken loads saved OAuth2 token from file
-func loadYouTubeToken() (*oauth2.Token, error) {
-	homeDir, err := os.UserHomeDir()
+// Status describes the saved login for the scope of opts without starting a
+// new one. It returns auth.ErrNotLoggedIn when no token is saved. The status
+// is returned even when the channel lookup fails.
+func Status(ctx context.Context, opts Options) (*auth.Status, error) {
+	client, status, err := savedClient(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
+	status.Account, err = client.ChannelTitle(ctx)
+	return status, err
+}
+
+// Refresh exchanges the refresh token for a new access token and saves it
+func Refresh(ctx context.Context, opts Options) (*auth.Status, error) {
+	scope, name := opts.scope()
 
-	tokenFile := filepath.Join(homeDir, ".spotomusic_youtube_token.json")
-	data, err := os.ReadFile(tokenFile)
+	config, err := oauthConfig(scope)
 	if err != nil {
 		return nil, err
 	}
-
-	var token oauth2.Token
-	if err := json.Unmarshal(data, &token); err != nil {
+	file, err := tokenFile(name)
+	if err != nil {
 		return nil, err
 	}
-
-	return &token, nil
+	saved, err := file.Load()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Refresh(ctx, config, &saved.Token); err != nil {
+		return nil, fmt.Errorf("YouTube token yenilenemedi: %v", err)
+	}
+	return Status(ctx, opts)
 }
 
-// saveYouTubeToken saves OAuth2 token to file
-func saveYouTubeToken(token *oauth2.Token) error {
-	homeDir, err := os.UserHomeDir()
+// savedClient creates a client from the saved token of the scope of opts
+// only, never logging in
+func savedClient(ctx context.Context, opts Options) (*Client, *auth.Status, error) {
+	scope, name := opts.scope()
+
+	file, err := tokenFile(name)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	saved, err := file.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := oauthConfig(scope)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	tokenFile := filepath.Join(homeDir, ".spotomusic_youtube_token.json")
-	data, err := json.Marshal(token)
+	client, err := newOAuthClient(ctx, config, file, &saved.Token)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	scopes := saved.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{scope}
 	}
+	return client, &auth.Status{
+		Service:     "youtube",
+		Location:    file.Location(),
+		Scopes:      scopes,
+		Expiry:      saved.Expiry,
+		Refreshable: saved.RefreshToken != "",
+	}, nil
+}
 
-	return os.WriteFile(tokenFile, data, 0600)
+// authenticateYouTube performs the OAuth2 login for YouTube. Google accepts
+// any loopback port for desktop clients, so the callback server listens on a
+// free one.
+func authenticateYouTube(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	return auth.Loopback{}.Login(ctx, config)
+}
+
+// Secret names in the store of a profile: one token per OAuth scope and
+// the OAuth client. The names are the file names of earlier versions.
+const (
+	youtubeTokenFile         = ".spotomusic_youtube_token.json"
+	youtubeReadOnlyTokenFile = ".spotomusic_youtube_readonly_token.json"
+	credentialsSecret        = ".spotomusic_youtube_credentials.json"
+)
+
+// tokenFile returns a token of the active profile
+func tokenFile(name string) (auth.SavedToken, error) {
+	active := profile.Active()
+	if active.Dir == "" {
+		return auth.SavedToken{}, fmt.Errorf("home directory bulunamadı")
+	}
+	return auth.SavedToken{Store: active.Secrets, Name: name}, nil
 }
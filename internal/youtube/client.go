@@ -2,20 +2,28 @@ package youtube
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
-	"os"
-	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
-	"golang.org/x/oauth2/google"
+	"spotomusic/internal/auth"
+
+	"golang.org/x/oauth2"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 )
 
 type Client struct {
 	service *youtube.Service
 	httpClient *http.Client
+	// authenticated is false for API key clients
+	authenticated bool
 }
 
 type YouTubePlaylist struct {
@@ -23,86 +31,228 @@ type YouTubePlaylist struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	VideoCount  int    `json:"video_count"`
+	// Privacy is public, unlisted or private; empty when unknown
+	Privacy string `json:"privacy,omitempty"`
 }
 
 type YouTubeVideo struct {
 	ID          string `json:"id"`
 	Title       string `json:"title"`
+	ChannelID   string `json:"channel_id"`
 	ChannelName string `json:"channel_name"`
 	Duration    string `json:"duration"`
 	URL         string `json:"url"`
 }
 
-// NewClient creates a new YouTube client with OAuth2 authentication
-func NewClient() (*Client, error) {
-	ctx := context.Background()
+// PlaylistItem is a video at a position in a playlist
+type PlaylistItem struct {
+	ID       string       `json:"id"`
+	Position int          `json:"position"`
+	Video    YouTubeVideo `json:"video"`
+	// PrivacyStatus is the privacy of the video itself, "private" for
+	// videos the user can no longer watch
+	PrivacyStatus string `json:"privacy_status,omitempty"`
+}
+
+// Unavailable reports whether the video was deleted or made private
+func (i PlaylistItem) Unavailable() bool {
+	if i.PrivacyStatus == "private" || i.PrivacyStatus == "privacyStatusUnspecified" {
+		return true
+	}
+	// Deleted videos keep a placeholder title but lose their owner
+	return i.Video.ChannelName == "" && (i.Video.Title == "Deleted video" || i.Video.Title == "Private video")
+}
+
+// VideoDetails holds the per-video data that search results lack
+type VideoDetails struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	ChannelID   string        `json:"channel_id"`
+	ChannelName string        `json:"channel_name"`
+	Duration    time.Duration `json:"duration"`
+	// PrivacyStatus is public, unlisted or private
+	PrivacyStatus string `json:"privacy_status"`
+	// UploadStatus is "processed" for playable videos and e.g. "rejected"
+	// or "deleted" otherwise
+	UploadStatus string `json:"upload_status"`
+	// AllowedRegions and BlockedRegions are ISO 3166-1 alpha-2 codes
+	AllowedRegions []string `json:"allowed_regions,omitempty"`
+	BlockedRegions []string `json:"blocked_regions,omitempty"`
+}
+
+// maxVideosPerRequest is the videos.list limit for IDs per call
+const maxVideosPerRequest = 50
 
-	// Load credentials from environment or file
-	credentialsJSON := os.Getenv("YOUTUBE_CREDENTIALS_JSON")
-	if credentialsJSON == "" {
-		// Try to load from file
-		homeDir, err := os.UserHomeDir()
+// isoDuration matches the ISO 8601 durations used by the YouTube API
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseDuration converts an ISO 8601 duration such as "PT3M53S"
+func ParseDuration(value string) (time.Duration, error) {
+	parts := isoDuration.FindStringSubmatch(value)
+	if parts == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if parts[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(parts[i+1])
 		if err != nil {
-			return nil, fmt.Errorf("home directory bulunamadı: %v", err)
+			return 0, fmt.Errorf("invalid duration %q", value)
 		}
-		
-		credsFile := filepath.Join(homeDir, ".spotomusic_youtube_credentials.json")
-		data, err := os.ReadFile(credsFile)
+		duration += time.Duration(n) * unit
+	}
+	return duration, nil
+}
+
+// SearchOptions narrows a video search
+type SearchOptions struct {
+	// MusicOnly restricts results to the Music category (videoCategoryId=10)
+	MusicOnly bool
+}
+
+// musicCategoryID is the YouTube video category for music
+const musicCategoryID = "10"
+
+// Options configures how the client authenticates
+type Options struct {
+	// ReadOnly only requests the youtube.readonly scope
+	ReadOnly bool
+	// APIKey enables key-based access without OAuth when ReadOnly is set.
+	// Only public data such as search results is available then.
+	APIKey string
+}
+
+// NewClient creates a new YouTube client with OAuth2 authentication.
+// ctx bounds the interactive login; API calls take their own context.
+func NewClient(ctx context.Context, opts Options) (*Client, error) {
+	if opts.ReadOnly && opts.APIKey != "" {
+		service, err := youtube.NewService(ctx, option.WithAPIKey(opts.APIKey))
 		if err != nil {
-			return nil, fmt.Errorf("YouTube credentials dosyası bulunamadı. Lütfen %s dosyasını oluşturun", credsFile)
+			return nil, fmt.Errorf("YouTube service oluşturulamadı: %v", err)
 		}
-		credentialsJSON = string(data)
+		return &Client{service: service}, nil
 	}
 
-	// Parse credentials
-	config, err := google.ConfigFromJSON([]byte(credentialsJSON), youtube.YoutubeScope)
+	scope, name := opts.scope()
+
+	config, err := oauthConfig(scope)
 	if err != nil {
-		return nil, fmt.Errorf("credentials parse edilemedi: %v", err)
+		return nil, err
 	}
-
-	// Check for saved token
-	token, err := loadYouTubeToken()
+	file, err := tokenFile(name)
 	if err != nil {
+		return nil, err
+	}
+
+	// Check for saved token. A full-access token also covers read-only use.
+	saved, err := file.Load()
+	if err != nil && opts.ReadOnly {
+		if full, fullErr := tokenFile(youtubeTokenFile); fullErr == nil {
+			if saved, err = full.Load(); err == nil {
+				file = full
+			}
+		}
+	}
+	var token *oauth2.Token
+	if err == nil {
+		token = &saved.Token
+	} else {
 		// No saved token, need to authenticate
-		token, err = authenticateYouTube(config)
+		token, err = authenticateYouTube(ctx, config)
 		if err != nil {
 			return nil, fmt.Errorf("YouTube authentication failed: %v", err)
 		}
 		// Save token for future use
-		if err := saveYouTubeToken(token); err != nil {
+		if err := file.Save(token); err != nil {
 			fmt.Printf("Warning: YouTube token kaydedilemedi: %v\n", err)
 		}
 	}
 
-	// Create HTTP client with token
-	httpClient := config.Client(ctx, token)
-	
-	// Create YouTube service
-	service, err := youtube.New(httpClient)
+	return newOAuthClient(ctx, config, file, token)
+}
+
+// newOAuthClient creates a client acting with token. Refreshed tokens are
+// saved to file so they outlive the run.
+func newOAuthClient(ctx context.Context, config *oauth2.Config, file auth.SavedToken, token *oauth2.Token) (*Client, error) {
+	// Token refreshes must outlive ctx, so only per-call contexts cancel
+	// requests
+	background := context.WithoutCancel(ctx)
+	httpClient := oauth2.NewClient(background, file.TokenSource(background, config, token))
+
+	service, err := youtube.NewService(ctx, option.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("YouTube service oluşturulamadı: %v", err)
 	}
 
 	return &Client{
-		service:    service,
-		httpClient: httpClient,
+		service:       service,
+		httpClient:    httpClient,
+		authenticated: true,
 	}, nil
 }
 
-// CreatePlaylist creates a new playlist on YouTube
-func (c *Client) CreatePlaylist(title, description string) (*YouTubePlaylist, error) {
+// ChannelTitle returns the name of the logged-in user's channel
+func (c *Client) ChannelTitle(ctx context.Context) (string, error) {
+	if !c.authenticated {
+		return "", fmt.Errorf("the channel is only known with an OAuth login, not an API key")
+	}
+
+	response, err := c.service.Channels.List([]string{"snippet"}).Mine(true).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("channel alınamadı: %v", err)
+	}
+	if len(response.Items) == 0 {
+		return "", fmt.Errorf("the account has no YouTube channel")
+	}
+	return response.Items[0].Snippet.Title, nil
+}
+
+// Authenticated reports whether the client acts on behalf of a user. API key
+// clients cannot see the user's playlists.
+func (c *Client) Authenticated() bool {
+	return c.authenticated
+}
+
+// Playlist privacy statuses
+const (
+	PrivacyPrivate  = "private"
+	PrivacyUnlisted = "unlisted"
+	PrivacyPublic   = "public"
+)
+
+// ParsePrivacy checks a playlist privacy status; empty means private
+func ParsePrivacy(value string) (string, error) {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "":
+		return PrivacyPrivate, nil
+	case PrivacyPrivate, PrivacyUnlisted, PrivacyPublic:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown privacy %q (expected public, unlisted or private)", value)
+}
+
+// CreatePlaylist creates a new playlist on YouTube. An empty privacy
+// creates a private playlist.
+func (c *Client) CreatePlaylist(ctx context.Context, title, description, privacy string) (*YouTubePlaylist, error) {
+	if privacy == "" {
+		privacy = PrivacyPrivate // Private by default
+	}
 	playlist := &youtube.Playlist{
 		Snippet: &youtube.PlaylistSnippet{
 			Title:       title,
 			Description: description,
 		},
 		Status: &youtube.PlaylistStatus{
-			PrivacyStatus: "private", // Private by default
+			PrivacyStatus: privacy,
 		},
 	}
 
 	call := c.service.Playlists.Insert([]string{"snippet", "status"}, playlist)
-	result, err := call.Do()
+	result, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("playlist oluşturulamadı: %v", err)
 	}
@@ -112,19 +262,58 @@ func (c *Client) CreatePlaylist(title, description string) (*YouTubePlaylist, er
 		Title:       result.Snippet.Title,
 		Description: result.Snippet.Description,
 		VideoCount:  0,
+		Privacy:     result.Status.PrivacyStatus,
+	}, nil
+}
+
+// UpdatePlaylist changes the title and description of a playlist. The API
+// replaces the whole snippet, so the current one is sent back with only
+// those two fields changed to keep e.g. the default language.
+func (c *Client) UpdatePlaylist(ctx context.Context, playlistID, title, description string) (*YouTubePlaylist, error) {
+	response, err := c.service.Playlists.List([]string{"snippet"}).
+		Id(playlistID).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("playlist alınamadı: %w", err)
+	}
+	if len(response.Items) == 0 {
+		return nil, fmt.Errorf("playlist bulunamadı: %s", playlistID)
+	}
+
+	snippet := response.Items[0].Snippet
+	snippet.Title = title
+	snippet.Description = description
+	playlist := &youtube.Playlist{
+		Id:      playlistID,
+		Snippet: snippet,
+	}
+
+	result, err := c.service.Playlists.Update([]string{"snippet"}, playlist).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("playlist güncellenemedi: %w", err)
+	}
+
+	return &YouTubePlaylist{
+		ID:          result.Id,
+		Title:       result.Snippet.Title,
+		Description: result.Snippet.Description,
 	}, nil
 }
 
 // SearchVideo searches for a video on YouTube
-func (c *Client) SearchVideo(query string) ([]YouTubeVideo, error) {
+func (c *Client) SearchVideo(ctx context.Context, query string, opts SearchOptions) ([]YouTubeVideo, error) {
 	call := c.service.Search.List([]string{"snippet"}).
 		Q(query).
 		Type("video").
 		MaxResults(5) // Limit to 5 results for better matching
+	if opts.MusicOnly {
+		call = call.VideoCategoryId(musicCategoryID)
+	}
 
-	response, err := call.Do()
+	response, err := call.Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("video search failed: %v", err)
+		return nil, fmt.Errorf("video search failed: %w", err)
 	}
 
 	var videos []YouTubeVideo
@@ -132,6 +321,7 @@ func (c *Client) SearchVideo(query string) ([]YouTubeVideo, error) {
 		videos = append(videos, YouTubeVideo{
 			ID:          item.Id.VideoId,
 			Title:       item.Snippet.Title,
+			ChannelID:   item.Snippet.ChannelId,
 			ChannelName: item.Snippet.ChannelTitle,
 			Duration:    "", // Duration not available in search results
 			URL:         fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.Id.VideoId),
@@ -141,8 +331,21 @@ func (c *Client) SearchVideo(query string) ([]YouTubeVideo, error) {
 	return videos, nil
 }
 
-// AddVideoToPlaylist adds a video to a playlist
-func (c *Client) AddVideoToPlaylist(playlistID, videoID string) error {
+// AddVideoToPlaylist adds a video to a playlist and returns the ID of the
+// new playlist item. The ID is empty when the video was already present.
+func (c *Client) AddVideoToPlaylist(ctx context.Context, playlistID, videoID string) (string, error) {
+	return c.insertPlaylistItem(ctx, playlistID, videoID, nil)
+}
+
+// InsertVideoAt adds a video at a zero-based position of a playlist and
+// returns the ID of the new playlist item
+func (c *Client) InsertVideoAt(ctx context.Context, playlistID, videoID string, position int) (string, error) {
+	pos := int64(position)
+	return c.insertPlaylistItem(ctx, playlistID, videoID, &pos)
+}
+
+// insertPlaylistItem appends a video, or inserts it at position when set
+func (c *Client) insertPlaylistItem(ctx context.Context, playlistID, videoID string, position *int64) (string, error) {
 	playlistItem := &youtube.PlaylistItem{
 		Snippet: &youtube.PlaylistItemSnippet{
 			PlaylistId: playlistID,
@@ -152,27 +355,163 @@ func (c *Client) AddVideoToPlaylist(playlistID, videoID string) error {
 			},
 		},
 	}
+	if position != nil {
+		playlistItem.Snippet.Position = *position
+		// Position 0 is a zero value and would be dropped otherwise
+		playlistItem.Snippet.ForceSendFields = []string{"Position"}
+	}
 
 	call := c.service.PlaylistItems.Insert([]string{"snippet"}, playlistItem)
-	_, err := call.Do()
+	result, err := call.Context(ctx).Do()
 	if err != nil {
 		// Check if it's a duplicate error
 		if googleapi.IsNotModified(err) || strings.Contains(err.Error(), "already exists") {
-			return nil // Ignore duplicate errors
+			return "", nil // Ignore duplicate errors
 		}
-		return fmt.Errorf("video playlist'e eklenemedi: %v", err)
+		return "", fmt.Errorf("video playlist'e eklenemedi: %w", err)
 	}
 
+	return result.Id, nil
+}
+
+// DeletePlaylistItem removes a single item from a playlist
+func (c *Client) DeletePlaylistItem(ctx context.Context, playlistItemID string) error {
+	if err := c.service.PlaylistItems.Delete(playlistItemID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("playlist item silinemedi: %w", err)
+	}
 	return nil
 }
 
+// DeletePlaylist deletes a playlist and all of its items
+func (c *Client) DeletePlaylist(ctx context.Context, playlistID string) error {
+	if err := c.service.Playlists.Delete(playlistID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("playlist silinemedi: %w", err)
+	}
+	return nil
+}
+
+// GetVideoDetails looks up videos by ID. Videos that no longer exist are
+// missing from the returned map.
+func (c *Client) GetVideoDetails(ctx context.Context, videoIDs []string) (map[string]VideoDetails, error) {
+	details := make(map[string]VideoDetails)
+	for start := 0; start < len(videoIDs); start += maxVideosPerRequest {
+		end := start + maxVideosPerRequest
+		if end > len(videoIDs) {
+			end = len(videoIDs)
+		}
+
+		response, err := c.service.Videos.List([]string{"snippet", "contentDetails", "status"}).
+			Id(videoIDs[start:end]...).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, fmt.Errorf("video bilgileri alınamadı: %v", err)
+		}
+
+		for _, video := range response.Items {
+			detail := VideoDetails{ID: video.Id}
+			if video.Snippet != nil {
+				detail.Title = video.Snippet.Title
+				detail.ChannelID = video.Snippet.ChannelId
+				detail.ChannelName = video.Snippet.ChannelTitle
+			}
+			if video.ContentDetails != nil {
+				// Live streams have no parseable duration; leave it at zero
+				detail.Duration, _ = ParseDuration(video.ContentDetails.Duration)
+				if restriction := video.ContentDetails.RegionRestriction; restriction != nil {
+					detail.AllowedRegions = restriction.Allowed
+					detail.BlockedRegions = restriction.Blocked
+				}
+			}
+			if video.Status != nil {
+				detail.PrivacyStatus = video.Status.PrivacyStatus
+				detail.UploadStatus = video.Status.UploadStatus
+			}
+			details[video.Id] = detail
+		}
+	}
+	return details, nil
+}
+
+// GetPlaylist retrieves a single playlist by ID
+func (c *Client) GetPlaylist(ctx context.Context, playlistID string) (*YouTubePlaylist, error) {
+	response, err := c.service.Playlists.List([]string{"snippet", "contentDetails", "status"}).
+		Id(playlistID).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("playlist alınamadı: %w", err)
+	}
+	if len(response.Items) == 0 {
+		return nil, fmt.Errorf("playlist bulunamadı: %s", playlistID)
+	}
+
+	playlist := response.Items[0]
+	return &YouTubePlaylist{
+		ID:          playlist.Id,
+		Title:       playlist.Snippet.Title,
+		Description: playlist.Snippet.Description,
+		VideoCount:  int(playlist.ContentDetails.ItemCount),
+		Privacy:     playlist.Status.PrivacyStatus,
+	}, nil
+}
+
+// GetPlaylistItems retrieves every item of a playlist in playlist order
+func (c *Client) GetPlaylistItems(ctx context.Context, playlistID string) ([]PlaylistItem, error) {
+	var items []PlaylistItem
+	pageToken := ""
+	for {
+		call := c.service.PlaylistItems.List([]string{"snippet", "status"}).
+			PlaylistId(playlistID).
+			MaxResults(50)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		response, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("playlist items alınamadı: %v", err)
+		}
+
+		for _, item := range response.Items {
+			videoID := item.Snippet.ResourceId.VideoId
+			playlistItem := PlaylistItem{
+				ID:       item.Id,
+				Position: int(item.Snippet.Position),
+				Video: YouTubeVideo{
+					ID:    videoID,
+					Title: item.Snippet.Title,
+					// The snippet channel is the playlist owner; the video
+					// owner is what identifies Topic channels
+					ChannelID:   item.Snippet.VideoOwnerChannelId,
+					ChannelName: item.Snippet.VideoOwnerChannelTitle,
+					URL:         fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID),
+				},
+			}
+			if item.Status != nil {
+				playlistItem.PrivacyStatus = item.Status.PrivacyStatus
+			}
+			items = append(items, playlistItem)
+		}
+
+		pageToken = response.NextPageToken
+		if pageToken == "" {
+			return items, nil
+		}
+	}
+}
+
 // GetUserPlaylists retrieves all playlists for the authenticated user
-func (c *Client) GetUserPlaylists() ([]YouTubePlaylist, error) {
+func (c *Client) GetUserPlaylists(ctx context.Context) ([]YouTubePlaylist, error) {
+	if !c.authenticated {
+		return nil, fmt.Errorf("listing your playlists requires an OAuth login, not an API key")
+	}
+
 	call := c.service.Playlists.List([]string{"snippet", "contentDetails"}).
 		Mine(true).
 		MaxResults(50)
 
-	response, err := call.Do()
+	response, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("playlists alınamadı: %v", err)
 	}
@@ -191,8 +530,8 @@ func (c *Client) GetUserPlaylists() ([]YouTubePlaylist, error) {
 }
 
 // PlaylistExists checks if a playlist with the given title exists
-func (c *Client) PlaylistExists(title string) (bool, *YouTubePlaylist, error) {
-	playlists, err := c.GetUserPlaylists()
+func (c *Client) PlaylistExists(ctx context.Context, title string) (bool, *YouTubePlaylist, error) {
+	playlists, err := c.GetUserPlaylists(ctx)
 	if err != nil {
 		return false, nil, err
 	}
@@ -205,3 +544,35 @@ func (c *Client) PlaylistExists(title string) (bool, *YouTubePlaylist, error) {
 
 	return false, nil, nil
 }
+
+// IsNotFound reports whether a call failed because the playlist, item or
+// video does not exist (any more)
+func IsNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// IsRetryable reports whether a failed API call may succeed when repeated:
+// server errors, rate limiting and network failures. Quota exhaustion and
+// cancellation are final.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code >= 500 || apiErr.Code == http.StatusTooManyRequests {
+			return true
+		}
+		for _, item := range apiErr.Errors {
+			if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}